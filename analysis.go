@@ -0,0 +1,619 @@
+package rats
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/woozymasta/semver"
+)
+
+// Unparseable returns the tags that pass the raw prefilter
+// (VPrefix/Include/Exclude/Ignore/signatures) but are not valid SemVer,
+// in input order. Useful to surface garbage in a registry listing.
+func Unparseable(in []string, opt Options) []string {
+	opt = opt.normalized()
+
+	rs, _ := parseTags(preFilterRaw(in, opt), opt)
+	if opt.MatchCanonical {
+		rs, _ = filterCanonical(rs, opt)
+	}
+	_, other := splitSemver(rs)
+	if other == nil {
+		return []string{}
+	}
+
+	return other
+}
+
+// PrefixConflicts returns pairs {"v1.2.3", "1.2.3"} for every SemVer tag that
+// is published both with and without a leading 'v'. Forms must match exactly
+// after the prefix, so rolling shorthands ("1.2" vs "v1.2.0") are not reported.
+// Pairs are ordered by the first appearance of either form; for repeated
+// spellings the first seen one is used.
+func PrefixConflicts(in []string) [][2]string {
+	type forms struct{ v, plain string }
+
+	by := make(map[string]*forms, len(in))
+	keys := make([]string, 0, len(in))
+
+	for _, s := range in {
+		v, ok := semver.Parse(s)
+		if !ok || !v.Valid {
+			continue
+		}
+
+		key := s
+		if v.HasV() {
+			key = s[1:]
+		}
+
+		f, seen := by[key]
+		if !seen {
+			f = &forms{}
+			by[key] = f
+			keys = append(keys, key)
+		}
+
+		if v.HasV() {
+			if f.v == "" {
+				f.v = s
+			}
+		} else if f.plain == "" {
+			f.plain = s
+		}
+	}
+
+	out := make([][2]string, 0)
+	for _, k := range keys {
+		if f := by[k]; f.v != "" && f.plain != "" {
+			out = append(out, [2]string{f.v, f.plain})
+		}
+	}
+
+	return out
+}
+
+// RollingPointers maps rolling tags to the concrete version they should point
+// at: "latest" -> overall best, "MAJOR" -> best of the major, "MAJOR.MINOR" ->
+// best of the minor. Values are rendered per output options.
+// All filters of opt apply; Depth, Sort and Limit are ignored.
+func RollingPointers(in []string, opt Options) map[string]string {
+	opt.Depth = DepthPatch
+	opt.Sort = SortNone
+	opt.Limit = 0
+	opt = opt.normalized()
+
+	best := make(map[string]rec, 16)
+	pick := func(k string, r rec) {
+		b, ok := best[k]
+		if !ok {
+			best[k] = r
+			return
+		}
+
+		if c := compareRecs(&r, &b); c > 0 || (c == 0 && r.idx < b.idx) {
+			best[k] = r
+		}
+	}
+
+	for _, r := range selectRecs(in, opt) {
+		if !r.ver.Valid {
+			continue
+		}
+
+		pick("latest", r)
+		pick(groupKey(r.ver.Major, 0, DepthMajor), r)
+		pick(groupKey(r.ver.Major, r.ver.Minor, DepthMinor), r)
+	}
+
+	out := make(map[string]string, len(best))
+	for k, r := range best {
+		out[k] = renderRec(&r, opt)
+	}
+
+	return out
+}
+
+// Compare parses two tags and compares them by SemVer precedence
+// (shorthand and a leading 'v' accepted, build ignored).
+// Returns -1 if a < b, 0 if equal, +1 if a > b, or an error naming the invalid tag.
+func Compare(a, b string) (int, error) {
+	va, ok := semver.Parse(a)
+	if !ok || !va.Valid {
+		return 0, fmt.Errorf("invalid version %q", a)
+	}
+
+	vb, ok := semver.Parse(b)
+	if !ok || !vb.Valid {
+		return 0, fmt.Errorf("invalid version %q", b)
+	}
+
+	return va.Compare(vb), nil
+}
+
+// Max returns the greatest valid SemVer tag of in by plain precedence
+// (prereleases take part, no gating or filters), as the original string.
+// Among equal versions the first seen wins. Returns false when none is valid.
+func Max(in []string) (string, bool) {
+	return extreme(in, 1)
+}
+
+// Min is Max for the lowest version.
+func Min(in []string) (string, bool) {
+	return extreme(in, -1)
+}
+
+// extreme returns the first greatest (sign 1) or lowest (sign -1) valid tag.
+func extreme(in []string, sign int) (string, bool) {
+	var best semver.Semver
+	found := false
+
+	for _, s := range in {
+		v, ok := semver.Parse(s)
+		if !ok || !v.Valid {
+			continue
+		}
+
+		if !found || v.Compare(best)*sign > 0 {
+			best, found = v, true
+		}
+	}
+
+	return best.Original, found
+}
+
+// BestUpgrade returns the highest tag of available, selected with opt, that is
+// strictly greater than current, rendered per opt. With opt.SameMajor it must
+// share the major of current. Depth, Sort and Limit are ignored; use Format,
+// Range or Constraint for further policy. Returns false when current is not
+// SemVer or no upgrade exists.
+func BestUpgrade(current string, available []string, opt Options) (string, bool) {
+	cur, ok := semver.Parse(current)
+	if !ok || !cur.Valid {
+		return "", false
+	}
+
+	opt.Depth = DepthPatch
+	opt.Sort = SortNone
+	opt.Limit = 0
+	opt = opt.normalized()
+
+	var best *rec
+	rs := selectRecs(available, opt)
+	for i := range rs {
+		r := &rs[i]
+		if !r.ver.Valid || r.ver.Compare(cur) <= 0 {
+			continue
+		}
+
+		if opt.SameMajor && r.ver.Major != cur.Major {
+			continue
+		}
+
+		if best == nil || compareRecs(r, best) > 0 {
+			best = r
+		}
+	}
+
+	if best == nil {
+		return "", false
+	}
+
+	return renderRec(best, opt), true
+}
+
+// Gaps reports missing release numbers inside each series of the selection,
+// ascending, as [first, last] missing versions of every contiguous hole.
+// by picks the granularity: DepthPatch (default) looks for absent patches
+// within each MAJOR.MINOR, DepthMinor for absent minors (X.Y.0) within each
+// major, DepthMajor for absent majors (X.0.0). Only holes between published
+// versions are reported, never before the first or after the last.
+// Prereleases are ignored; all filters of opt apply, Depth/Sort/Limit do not.
+//
+// E.g. 1.2.0, 1.2.3 -> [[1.2.1, 1.2.2]] with DepthPatch.
+func Gaps(in []string, by Depth, opt Options) [][2]semver.Semver {
+	opt.Depth = DepthPatch
+	opt.Sort = SortAsc
+	opt.Limit = 0
+	opt = opt.normalized()
+
+	// distinct (series, number) pairs in ascending order
+	type point struct{ maj, min, n int }
+	pts := make([]point, 0, len(in))
+	for _, r := range selectRecs(in, opt) {
+		v := r.ver
+		if !v.Valid || has(v.Flags, semver.FlagHasPre) {
+			continue
+		}
+
+		p := point{maj: v.Major, min: v.Minor, n: v.Patch}
+		switch by {
+		case DepthMajor:
+			p = point{n: v.Major}
+		case DepthMinor:
+			p = point{maj: v.Major, n: v.Minor}
+		}
+
+		if len(pts) == 0 || pts[len(pts)-1] != p {
+			pts = append(pts, p)
+		}
+	}
+
+	mk := func(maj, minor, n int) semver.Semver {
+		switch by {
+		case DepthMajor:
+			return mustVersion(n, 0, 0, "")
+		case DepthMinor:
+			return mustVersion(maj, n, 0, "")
+		default:
+			return mustVersion(maj, minor, n, "")
+		}
+	}
+
+	out := make([][2]semver.Semver, 0)
+	for i := 1; i < len(pts); i++ {
+		a, b := pts[i-1], pts[i]
+		if a.maj != b.maj || a.min != b.min || b.n-a.n < 2 {
+			continue
+		}
+
+		out = append(out, [2]semver.Semver{mk(a.maj, a.min, a.n+1), mk(a.maj, a.min, b.n-1)})
+	}
+
+	return out
+}
+
+// Histogram counts the distinct versions selected by opt per series:
+// keys are "MAJOR.MINOR" when by is DepthMinor and "MAJOR" otherwise.
+// Aliases of one version ("1.2.3", "v1.2.3+b1") count once; all filters
+// of opt apply, Depth/DedupByMinor/Limit do not. Non-semver tags are ignored.
+//
+// E.g. 1.2.0, v1.2.1, 1.2.1, 1.3.0 -> {"1.2": 2, "1.3": 1} with DepthMinor.
+func Histogram(in []string, by Depth, opt Options) map[string]int {
+	opt.Depth = DepthNone
+	opt.DedupByMinor = false
+	opt.Deduplicate = true
+	opt.Sort = SortNone
+	opt.Limit = 0
+	opt = opt.normalized()
+
+	out := make(map[string]int, 16)
+	for _, r := range selectRecs(in, opt) {
+		if r.ver.Valid {
+			out[groupKey(r.ver.Major, r.ver.Minor, by)]++
+		}
+	}
+
+	return out
+}
+
+// Majors returns the distinct major numbers of the SemVer tags selected by
+// opt, descending (ascending with opt.Sort SortAsc). All filters of opt
+// apply, Depth/DedupByMinor/Limit do not, so prereleases count unless Format
+// gates releases. Non-semver tags are ignored. Never nil.
+func Majors(in []string, opt Options) []int {
+	return seriesNumbers(in, opt, func(v semver.Semver) (int, bool) {
+		return v.Major, true
+	})
+}
+
+// Minors is Majors for the distinct minor numbers within major.
+func Minors(in []string, major int, opt Options) []int {
+	return seriesNumbers(in, opt, func(v semver.Semver) (int, bool) {
+		return v.Minor, v.Major == major
+	})
+}
+
+// seriesNumbers collects the distinct numbers pick returns for the selected
+// SemVer tags and orders them per Majors.
+func seriesNumbers(in []string, opt Options, pick func(semver.Semver) (int, bool)) []int {
+	asc := opt.Sort == SortAsc
+
+	opt.Depth = DepthNone
+	opt.DedupByMinor = false
+	opt.Sort = SortNone
+	opt.Limit = 0
+	opt = opt.normalized()
+
+	seen := make(map[int]struct{}, 16)
+	out := make([]int, 0, 16)
+	for _, r := range selectRecs(in, opt) {
+		if !r.ver.Valid {
+			continue
+		}
+
+		n, ok := pick(r.ver)
+		if _, dup := seen[n]; !ok || dup {
+			continue
+		}
+
+		seen[n] = struct{}{}
+		out = append(out, n)
+	}
+
+	slices.Sort(out)
+	if !asc {
+		slices.Reverse(out)
+	}
+
+	return out
+}
+
+// Diff compares two tag lists after the same filters and gating of opt and
+// returns the tags of newer missing from older (added) and of older missing
+// from newer (removed). SemVer tags are compared by version identity (a 'v'
+// prefix, shorthand and build metadata do not matter), others by raw string;
+// each identity is reported once, as its first alias. Both slices are ordered
+// per opt.Sort (input order with SortNone) and rendered per opt.
+// Depth and Limit are ignored.
+func Diff(older, newer []string, opt Options) (added, removed []string) {
+	opt.Depth = DepthPatch
+	opt.Deduplicate = true
+	opt.Limit = 0
+	opt = opt.normalized()
+
+	oldRecs := selectRecs(older, opt)
+	newRecs := selectRecs(newer, opt)
+
+	return diffRecs(newRecs, oldRecs, opt), diffRecs(oldRecs, newRecs, opt)
+}
+
+// diffRecs renders the records of a whose identity is absent from b.
+func diffRecs(a, b []rec, opt Options) []string {
+	type ident struct {
+		k   dkey
+		raw string
+	}
+	id := func(r *rec) ident {
+		if r.ver.Valid {
+			return ident{k: keyOf(r.ver)}
+		}
+
+		return ident{raw: r.raw}
+	}
+
+	seen := make(map[ident]struct{}, len(b))
+	for i := range b {
+		seen[id(&b[i])] = struct{}{}
+	}
+
+	out := make([]string, 0)
+	for i := range a {
+		k := id(&a[i])
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		out = append(out, renderRec(&a[i], opt))
+	}
+
+	return out
+}
+
+// Satisfies reports whether tag is a SemVer version inside r, with the same
+// shorthand-floor, exclusive-bound and ceiling-prerelease rules as Select.
+// A disabled Range accepts every version; non-semver tags never satisfy.
+func Satisfies(tag string, r Range) bool {
+	v, ok := semver.Parse(tag)
+	if !ok || !v.Valid {
+		return false
+	}
+
+	return !r.Enabled() || compileRange(r).contains(v)
+}
+
+// SatisfiesConstraint reports whether tag satisfies a constraint expression
+// (see ParseConstraint). Non-semver tags never do; an invalid constraint is an error.
+func SatisfiesConstraint(tag, constraint string) (bool, error) {
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+
+	v, _ := semver.Parse(tag)
+
+	return c.Check(v), nil
+}
+
+// SortKeys runs the Select pipeline and returns (raw, key) pairs in output
+// order, where key is the basis used for ordering: the canonical
+// "vMAJOR.MINOR.PATCH[-PRERELEASE]" for SemVer tags (build does not take part
+// in precedence) and the raw tag itself for lexically sorted non-semver tags.
+func SortKeys(in []string, opt Options) [][2]string {
+	opt = opt.normalized()
+
+	rs := selectLimited(in, opt)
+	out := make([][2]string, 0, len(rs))
+	for i := range rs {
+		key := rs[i].raw
+		if rs[i].ver.Valid {
+			key = rs[i].ver.Canonical()
+		}
+
+		out = append(out, [2]string{rs[i].raw, key})
+	}
+
+	return out
+}
+
+// SummarizeRange describes the selection as a union of contiguous ranges,
+// e.g. ">=1.2.0 <=1.3.0 || =2.0.1". A range is contiguous when no other
+// candidate version lies inside it; candidates are the input versions passing
+// the filters and gating of opt, before Range/Ranges/Constraint, aggregation
+// and Limit. So the result covers exactly the selected versions among the candidates.
+// Returns "" for an empty selection; non-semver tags are ignored.
+func SummarizeRange(in []string, opt Options) string {
+	opt = opt.normalized()
+
+	selected := make(map[dkey]struct{}, len(in))
+	for _, r := range selectLimited(in, opt) {
+		if r.ver.Valid {
+			selected[keyOf(r.ver)] = struct{}{}
+		}
+	}
+
+	if len(selected) == 0 {
+		return ""
+	}
+
+	// candidates, distinct and ascending
+	uOpt := opt
+	uOpt.Range = Range{}
+	uOpt.Ranges = nil
+	uOpt.Constraint = ""
+	uOpt.Depth = DepthPatch
+	uOpt.DedupByMinor = false
+	uOpt.Deduplicate = true
+	uOpt.Sort = SortAsc
+
+	sem := selectRecs(in, uOpt)
+
+	var b strings.Builder
+	var lo, hi *semver.Semver
+
+	flush := func() {
+		if lo == nil {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteString(" || ")
+		}
+
+		if lo.Compare(*hi) == 0 {
+			b.WriteString("=" + lo.SemVer())
+		} else {
+			b.WriteString(">=" + lo.SemVer() + " <=" + hi.SemVer())
+		}
+		lo, hi = nil, nil
+	}
+
+	for i := range sem {
+		v := &sem[i].ver
+		if !v.Valid {
+			continue // non-semver, wherever NonSemverPlacement puts them
+		}
+
+		if _, ok := selected[keyOf(*v)]; !ok {
+			flush()
+			continue
+		}
+
+		if lo == nil {
+			lo = v
+		}
+		hi = v
+	}
+	flush()
+
+	return b.String()
+}
+
+// Intersect returns the tags of desired, selected with opt, whose version is
+// SemVer-equal (build ignored) to some tag in available. Aliases match across
+// lists: "v1.2", "1.2.0" and "1.2.0+b1" are the same version. The result keeps
+// the Select order of desired (input order with SortNone), rendered per opt,
+// with Offset/Limit applied after intersecting. Non-semver tags never match.
+func Intersect(desired, available []string, opt Options) []string {
+	opt = opt.normalized()
+
+	avail, _ := parseTags(available, opt)
+	have := make(map[dkey]struct{}, len(avail))
+	for _, r := range avail {
+		if r.ver.Valid {
+			have[keyOf(r.ver)] = struct{}{}
+		}
+	}
+
+	rs := selectRecs(desired, opt)
+	out := make([]string, 0, len(rs))
+	for i := range rs {
+		if !rs[i].ver.Valid {
+			continue
+		}
+
+		if _, ok := have[keyOf(rs[i].ver)]; ok {
+			out = append(out, renderRec(&rs[i], opt))
+		}
+	}
+
+	return trimStrings(out, opt)
+}
+
+// Percentile sorts the selected SemVer versions ascending and returns the one
+// at percentile p (0..100, nearest-rank: p=0 is the oldest, p=100 the newest),
+// rendered per opt. opt.Sort and opt.Limit are ignored. Returns false for an
+// empty selection or p outside [0, 100].
+func Percentile(in []string, p float64, opt Options) (string, bool) {
+	if !(p >= 0 && p <= 100) { // also rejects NaN
+		return "", false
+	}
+
+	opt.Sort = SortAsc
+	opt.Limit = 0
+	opt = opt.normalized()
+
+	// semver records only, wherever NonSemverPlacement puts the others
+	rs := selectRecs(in, opt)
+	sem := rs[:0]
+	for _, r := range rs {
+		if r.ver.Valid {
+			sem = append(sem, r)
+		}
+	}
+
+	n := len(sem)
+	if n == 0 {
+		return "", false
+	}
+
+	i := int(math.Ceil(p/100*float64(n))) - 1
+	i = max(0, min(i, n-1))
+
+	return renderRec(&sem[i], opt), true
+}
+
+// Bump returns the version after tag when incrementing part (DepthMajor,
+// DepthMinor or DepthPatch) and resetting the lower parts to 0, as
+// "MAJOR.MINOR.PATCH" with the leading 'v' of tag kept and build dropped.
+// A prerelease is released instead when that is already the next version:
+// "1.2.3-rc.1" bumps its patch to "1.2.3", "1.3.0-rc.1" its minor to "1.3.0".
+// Returns false for non-semver tags and other depths.
+func Bump(tag string, part Depth) (string, bool) {
+	v, ok := semver.Parse(tag)
+	if !ok || !v.Valid {
+		return "", false
+	}
+
+	pre := v.HasPre()
+
+	var next semver.Semver
+	switch part {
+	case DepthPatch:
+		if pre {
+			next, ok = v.StripPre()
+		} else {
+			next, ok = v.BumpPatch()
+		}
+	case DepthMinor:
+		if pre && v.Patch == 0 {
+			next, ok = v.StripPre()
+		} else {
+			next, ok = v.BumpMinor()
+		}
+	case DepthMajor:
+		if pre && v.Minor == 0 && v.Patch == 0 {
+			next, ok = v.StripPre()
+		} else {
+			next, ok = v.BumpMajor()
+		}
+	default:
+		return "", false
+	}
+	if !ok {
+		return "", false
+	}
+
+	return next.Print(semver.PrintMaskRelease), true
+}
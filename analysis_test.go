@@ -0,0 +1,451 @@
+package rats
+
+import (
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/woozymasta/semver"
+)
+
+func TestUnparseable(t *testing.T) {
+	got := Unparseable([]string{"1.2.3.4", "foo", "1.2.3"}, Options{})
+	eqStrings(t, got, []string{"1.2.3.4", "foo"})
+
+	// prefilter applies first
+	got = Unparseable([]string{"1.2.3.4", "foo", "v1.2.3", sigTag()}, Options{
+		VPrefix:           PrefixNone,
+		ExcludeSignatures: true,
+	})
+	eqStrings(t, got, []string{"1.2.3.4", "foo"})
+
+	if got := Unparseable([]string{"1.2.3"}, Options{}); got == nil || len(got) != 0 {
+		t.Fatalf("want empty non-nil, got %#v", got)
+	}
+}
+
+func TestPrefixConflicts(t *testing.T) {
+	in := []string{"1.2.3", "v1.0.0", "v1.2.3", "1.3", "v1.3.0", "1.0.0", "V1.0.0", "foo", "vfoo", "2.0.0-rc.1", "v2.0.0-rc.1"}
+
+	got := PrefixConflicts(in)
+	want := [][2]string{
+		{"v1.2.3", "1.2.3"},
+		{"v1.0.0", "1.0.0"},
+		{"v2.0.0-rc.1", "2.0.0-rc.1"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("at %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if got := PrefixConflicts([]string{"1.2.3", "v1.2.4"}); len(got) != 0 {
+		t.Fatalf("want none, got %v", got)
+	}
+}
+
+func TestRollingPointers(t *testing.T) {
+	in := []string{"1.2.0", "1.2.3", "v1.3.0", "2.0.0", "2.1.0-rc.1", "2.1.0", "2.1.1", "latest"}
+
+	got := RollingPointers(in, Options{Format: FormatXYZ})
+	want := map[string]string{
+		"latest": "2.1.1",
+		"1":      "v1.3.0",
+		"1.2":    "1.2.3",
+		"1.3":    "v1.3.0",
+		"2":      "2.1.1",
+		"2.0":    "2.0.0",
+		"2.1":    "2.1.1",
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("%q -> %q, want %q", k, got[k], v)
+		}
+	}
+
+	// canonical output, Depth/Limit are ignored
+	got = RollingPointers(in, Options{Format: FormatXYZ, OutputCanonical: true, Depth: DepthLatest, Limit: 1})
+	if got["1"] != "v1.3.0" || got["1.2"] != "v1.2.3" || got["latest"] != "v2.1.1" {
+		t.Fatalf("canonical: got %v", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.10.0", -1},
+		{"v1.2", "1.2.0", 0},
+		{"1.2.3+a", "1.2.3+b", 0},
+		{"1.2.3", "1.2.3-rc.1", 1},
+		{"1.2.3-rc.2", "1.2.3-rc.10", -1},
+	}
+
+	for _, c := range cases {
+		got, err := Compare(c.a, c.b)
+		if err != nil {
+			t.Fatalf("Compare(%q,%q): %v", c.a, c.b, err)
+		}
+		if got != c.want {
+			t.Fatalf("Compare(%q,%q)=%d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+
+	if _, err := Compare("1.2.3", "foo"); err == nil {
+		t.Fatalf("want error for invalid tag")
+	}
+}
+
+func TestSortKeys(t *testing.T) {
+	in := []string{"1.2.9", "v1.2.10", "1.2", "1.3.0-rc.1+b7", "latest"}
+
+	got := SortKeys(in, Options{Sort: SortDesc})
+	want := [][2]string{
+		{"1.3.0-rc.1+b7", "v1.3.0-rc.1"},
+		{"v1.2.10", "v1.2.10"},
+		{"1.2.9", "v1.2.9"},
+		{"1.2", "v1.2.0"},
+		{"latest", "latest"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("at %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSummarizeRange(t *testing.T) {
+	in := []string{
+		"1.1.0", "1.2.0", "1.2.1", "1.2.9", "v1.3.0", "1.4.0-rc.1", "1.4.0", "2.0.0", "2.0.1", "2.1.0", "latest",
+	}
+
+	cases := []struct {
+		name string
+		opt  Options
+		want string
+	}{
+		{"range", Options{Range: Range{Min: "1.2", Max: "1.3"}}, ">=1.2.0 <=1.3.0"},
+		{"all", Options{}, ">=1.1.0 <=2.1.0"},
+		{"prerelease gap", Options{Format: FormatNone, Range: Range{Min: "1.3", Max: "2.0.0"}, Exclude: regexp.MustCompile(`-rc`)}, ">=1.3.0 <=2.0.0"},
+		{"release", Options{Format: FormatXYZ}, ">=1.1.0 <=2.1.0"},
+		{"prerelease excluded", Options{FilterSemver: true, Range: Range{Min: "1.3", Max: "2.0.0"}, Deduplicate: true, Depth: DepthMinor}, "=1.3.0 || >=1.4.0 <=2.0.0"},
+		{"single", Options{Range: Range{Min: "2.0.1", Max: "2.0.1"}}, "=2.0.1"},
+		{"disjoint", Options{Format: FormatXYZ, Depth: DepthMinor}, "=1.1.0 || >=1.2.9 <=1.4.0 || >=2.0.1 <=2.1.0"},
+		{"empty", Options{Range: Range{Min: "9"}}, ""},
+		{"non-semver first", Options{NonSemverPlacement: NonSemverPrepend, Range: Range{Min: "1.2", Max: "1.3"}}, ">=1.2.0 <=1.3.0"},
+		{"floating first", Options{NonSemverPlacement: NonSemverPrepend, FilterSemver: true, FloatingTags: []string{"latest"}}, ">=1.1.0 <=2.1.0"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := SummarizeRange(in, c.opt); got != c.want {
+				t.Fatalf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	desired := []string{"1.3", "v1.2.0", "2.0.0", "1.1.0", "latest", "3.0.0-rc.1"}
+	available := []string{"1.1.0", "v1.3.0", "1.2+meta", "1.2.0+b7", "3.0.0-rc.1", "latest"}
+
+	got := Intersect(desired, available, Options{})
+	eqStrings(t, got, []string{"1.3", "v1.2.0", "1.1.0", "3.0.0-rc.1"})
+
+	got = Intersect(desired, available, Options{Format: FormatAll, OutputCanonical: true, Sort: SortDesc, Limit: 2})
+	eqStrings(t, got, []string{"v1.3.0", "v1.2.0"})
+
+	if got := Intersect(desired, nil, Options{}); len(got) != 0 {
+		t.Fatalf("want empty, got %v", got)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	in := []string{"1.4.0", "1.0.0", "1.2.0", "1.3.0", "1.1.0", "latest", "2.0.0-rc.1"}
+	opt := Options{Format: FormatXYZ}
+
+	cases := []struct {
+		p    float64
+		want string
+	}{
+		{0, "1.0.0"},
+		{20, "1.0.0"},
+		{21, "1.1.0"},
+		{50, "1.2.0"},
+		{80, "1.3.0"},
+		{100, "1.4.0"},
+	}
+
+	for _, c := range cases {
+		got, ok := Percentile(in, c.p, opt)
+		if !ok || got != c.want {
+			t.Fatalf("Percentile(%v)=(%q,%v), want %q", c.p, got, ok, c.want)
+		}
+	}
+
+	for _, p := range []float64{-1, 101, math.NaN()} {
+		if _, ok := Percentile(in, p, opt); ok {
+			t.Fatalf("Percentile(%v): want false", p)
+		}
+	}
+
+	if _, ok := Percentile([]string{"latest"}, 50, Options{}); ok {
+		t.Fatalf("no semver: want false")
+	}
+}
+
+func TestPercentile_NonSemverPlacement(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"latest", "1.0.0", "edge", "1.1.0", "1.2.0", "stable"}
+
+	for _, place := range []NonSemverPlacement{NonSemverAppend, NonSemverPrepend, NonSemverDrop} {
+		for _, opt := range []Options{
+			{NonSemverPlacement: place},
+			{NonSemverPlacement: place, FilterSemver: true, FloatingTags: []string{"latest", "stable"}},
+		} {
+			for p, want := range map[float64]string{0: "1.0.0", 50: "1.1.0", 100: "1.2.0"} {
+				if got, ok := Percentile(in, p, opt); !ok || got != want {
+					t.Errorf("%v, floating %v: Percentile(%v)=(%q,%v), want %q", place, opt.FloatingTags, p, got, ok, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	cases := []struct {
+		tag  string
+		r    Range
+		want bool
+	}{
+		{"1.4.0", Range{Min: "1.4"}, true},
+		{"1.3.9", Range{Min: "1.4"}, false},
+		{"1.4.0-rc.1", Range{Min: "1.4"}, false},
+		{"1.4.0-rc.1", Range{Min: "1.4", IncludePrerelease: true}, true},
+		{"2.0.0", Range{Min: "1.4", Max: "2.0.0", MaxExclusive: true}, false},
+		{"1.4.0", Range{Min: "1.4.0", MinExclusive: true}, false},
+		{"v1.9", Range{Min: "1.4", Max: "2"}, true},
+		{"latest", Range{}, false},
+		{"1.0.0", Range{}, true},
+	}
+
+	for _, c := range cases {
+		if got := Satisfies(c.tag, c.r); got != c.want {
+			t.Fatalf("Satisfies(%q, %+v)=%v, want %v", c.tag, c.r, got, c.want)
+		}
+
+		// must agree with the bulk path
+		bulk := len(Select([]string{c.tag}, Options{FilterSemver: true, Range: c.r})) == 1
+		if bulk != c.want {
+			t.Fatalf("Select disagrees for %q %+v", c.tag, c.r)
+		}
+	}
+}
+
+func TestSatisfiesConstraint(t *testing.T) {
+	ok, err := SatisfiesConstraint("v1.5.2", ">=1.4 <2")
+	if err != nil || !ok {
+		t.Fatalf("got %v, %v; want true", ok, err)
+	}
+
+	if ok, _ := SatisfiesConstraint("latest", "*"); ok {
+		t.Fatalf("non-semver must not satisfy")
+	}
+
+	if _, err := SatisfiesConstraint("1.0.0", ">=1.y"); err == nil {
+		t.Fatalf("want constraint error")
+	}
+}
+
+func TestMaxMin(t *testing.T) {
+	in := []string{"latest", "1.2.3", "v2.0.0-rc.1", "1.10.0", "2.0.0-beta", "v1.2.3", "0.9"}
+
+	if got, ok := Max(in); !ok || got != "v2.0.0-rc.1" {
+		t.Fatalf("Max=%q,%v", got, ok)
+	}
+
+	if got, ok := Min(in); !ok || got != "0.9" {
+		t.Fatalf("Min=%q,%v", got, ok)
+	}
+
+	// ties: first seen
+	if got, _ := Max([]string{"1.2.3", "v1.2.3+b"}); got != "1.2.3" {
+		t.Fatalf("Max tie=%q", got)
+	}
+
+	if _, ok := Max([]string{"latest", "edge"}); ok {
+		t.Fatalf("Max of no semver must be false")
+	}
+}
+
+func TestBestUpgrade(t *testing.T) {
+	avail := []string{"1.2.3", "1.2.4", "v1.3.0", "1.4.0-rc.1", "2.0.0", "latest"}
+
+	if got, ok := BestUpgrade("1.2.3", avail, Options{}); !ok || got != "2.0.0" {
+		t.Fatalf("got %q,%v", got, ok)
+	}
+
+	if got, ok := BestUpgrade("v1.2.3", avail, Options{SameMajor: true}); !ok || got != "1.4.0-rc.1" {
+		t.Fatalf("SameMajor got %q,%v", got, ok)
+	}
+
+	// releases only
+	if got, ok := BestUpgrade("1.2.3", avail, Options{SameMajor: true, Format: FormatAll}); !ok || got != "v1.3.0" {
+		t.Fatalf("Format got %q,%v", got, ok)
+	}
+
+	if got, ok := BestUpgrade("1.2.3", avail, Options{Range: Range{Max: "1.2"}}); ok {
+		t.Fatalf("Range got %q, want no upgrade", got)
+	}
+
+	if _, ok := BestUpgrade("2.0.0", avail, Options{}); ok {
+		t.Fatalf("want no upgrade from the newest")
+	}
+
+	if _, ok := BestUpgrade("latest", avail, Options{}); ok {
+		t.Fatalf("non-semver current must fail")
+	}
+}
+
+func TestGaps(t *testing.T) {
+	in := []string{"1.2.0", "1.2.3", "v1.2.4", "1.2.7-rc.1", "1.2.8", "1.5.0", "1.5.1", "3.0.0", "latest"}
+
+	str := func(gs [][2]semver.Semver) []string {
+		out := make([]string, 0, len(gs))
+		for _, g := range gs {
+			out = append(out, g[0].SemVer()+".."+g[1].SemVer())
+		}
+		return out
+	}
+
+	eqStrings(t, str(Gaps(in, DepthPatch, Options{})), []string{"1.2.1..1.2.2", "1.2.5..1.2.7"})
+	eqStrings(t, str(Gaps(in, DepthMinor, Options{})), []string{"1.3.0..1.4.0"})
+	eqStrings(t, str(Gaps(in, DepthMajor, Options{})), []string{"2.0.0..2.0.0"})
+
+	// filters apply
+	eqStrings(t, str(Gaps(in, DepthPatch, Options{Range: Range{Min: "1.2.3"}})), []string{"1.2.5..1.2.7"})
+	eqStrings(t, str(Gaps([]string{"1.0.0"}, DepthPatch, Options{})), []string{})
+}
+
+func TestHistogram(t *testing.T) {
+	in := []string{"1.2.0", "v1.2.1", "1.2.1+b1", "1.3.0", "1.3.1-rc.1", "2.0.0", "latest"}
+
+	got := Histogram(in, DepthMinor, Options{})
+	want := map[string]int{"1.2": 2, "1.3": 2, "2.0": 1}
+	if !maps.Equal(got, want) {
+		t.Fatalf("minor: got %v, want %v", got, want)
+	}
+
+	got = Histogram(in, DepthMajor, Options{Format: FormatAll})
+	want = map[string]int{"1": 3, "2": 1}
+	if !maps.Equal(got, want) {
+		t.Fatalf("major releases: got %v, want %v", got, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	older := []string{"1.0.0", "v1.1.0", "1.2.0+b1", "edge", "latest"}
+	newer := []string{"1.1", "1.2.0+b2", "1.3.0", "v1.3.0", "2.0.0", "latest", "nightly"}
+
+	added, removed := Diff(older, newer, Options{})
+	eqStrings(t, added, []string{"1.3.0", "2.0.0", "nightly"})
+	eqStrings(t, removed, []string{"1.0.0", "edge"})
+
+	added, removed = Diff(older, newer, Options{FilterSemver: true, Sort: SortDesc})
+	eqStrings(t, added, []string{"2.0.0", "1.3.0"})
+	eqStrings(t, removed, []string{"1.0.0"})
+}
+
+func TestMajorsMinors(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"v1.2.3", "1.10.0", "2.0.0-rc.1", "v1.2.4", "0.9.1", "latest", "3", "1.3.0-beta"}
+
+	eqInts := func(got, want []int) {
+		t.Helper()
+		if !slices.Equal(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+
+	eqInts(Majors(in, Options{}), []int{3, 2, 1, 0})
+	eqInts(Majors(in, Options{Sort: SortAsc}), []int{0, 1, 2, 3})
+	eqInts(Majors(in, Options{Format: FormatAll}), []int{3, 1, 0}) // prereleases gated out
+	eqInts(Majors(in, DefaultOptions()), []int{3, 1, 0})           // Depth and Limit ignored
+
+	eqInts(Minors(in, 1, Options{}), []int{10, 3, 2})
+	eqInts(Minors(in, 1, Options{Format: FormatXYZ, Sort: SortAsc}), []int{2, 10})
+	eqInts(Minors(in, 7, Options{}), []int{})
+	eqInts(Majors(nil, Options{}), []int{})
+}
+
+func TestBump(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		tag  string
+		part Depth
+		want string
+	}{
+		{"1.2.3", DepthMinor, "1.3.0"},
+		{"1.2.3", DepthPatch, "1.2.4"},
+		{"1.2.3", DepthMajor, "2.0.0"},
+		{"v1.2.3+build.5", DepthPatch, "v1.2.4"},
+		{"1.2", DepthPatch, "1.2.1"},
+		{"v1", DepthMinor, "v1.1.0"},
+		{"1.2.3-rc.1", DepthPatch, "1.2.3"},
+		{"1.2.3-rc.1", DepthMinor, "1.3.0"},
+		{"1.3.0-rc.1", DepthMinor, "1.3.0"},
+		{"1.3.0-rc.1", DepthMajor, "2.0.0"},
+		{"2.0.0-beta", DepthMajor, "2.0.0"},
+	}
+	for _, c := range cases {
+		got, ok := Bump(c.tag, c.part)
+		if !ok || got != c.want {
+			t.Errorf("Bump(%q, %v) = %q, %v; want %q", c.tag, c.part, got, ok, c.want)
+		}
+	}
+
+	for _, c := range []struct {
+		tag  string
+		part Depth
+	}{{"latest", DepthPatch}, {"1.2.3", DepthLatest}, {"1.2.3", DepthNone}} {
+		if got, ok := Bump(c.tag, c.part); ok {
+			t.Errorf("Bump(%q, %v) = %q, want false", c.tag, c.part, got)
+		}
+	}
+}
+
+func TestHelpers_NonSemverFirst(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"latest", "1.0.0", "1.1.0", "1.2.0"}
+
+	for _, opt := range []Options{
+		{NonSemverPlacement: NonSemverPrepend},
+		{NonSemverPlacement: NonSemverPrepend, FilterSemver: true, FloatingTags: []string{"latest"}},
+	} {
+		if got, ok := Percentile(in, 50, opt); !ok || got != "1.1.0" {
+			t.Errorf("Percentile: got (%q, %v), want 1.1.0", got, ok)
+		}
+		if got := SummarizeRange(in, opt); got != ">=1.0.0 <=1.2.0" {
+			t.Errorf("SummarizeRange: got %q", got)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package rats
+
+import (
+	"errors"
+	"regexp"
+)
+
+// Option configures Options in New.
+type Option func(*Options) error
+
+// New builds Options from zero values (raw passthrough, see Options) by
+// applying opts in order, then checks the result with Validate:
+//
+//	opt, err := rats.New(
+//		rats.WithDefaults(),
+//		rats.WithDepth(rats.DepthMinor),
+//		rats.ReleaseOnly(),
+//		rats.WithRange("1.0", "2.0"),
+//		rats.WithIncludePattern(`^v?1\.`),
+//	)
+//
+// Errors of all options are joined; the Options are returned regardless.
+// The builder is sugar over the plain struct, which stays fully usable.
+func New(opts ...Option) (Options, error) {
+	var o Options
+	var errs []error
+	for _, fn := range opts {
+		errs = append(errs, fn(&o))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return o, err
+	}
+
+	return o, o.Validate()
+}
+
+// WithDefaults resets to DefaultOptions; put it first to build on top of them.
+func WithDefaults() Option {
+	return func(o *Options) error {
+		*o = DefaultOptions()
+		return nil
+	}
+}
+
+// WithDepth sets Depth.
+func WithDepth(d Depth) Option {
+	return func(o *Options) error {
+		o.Depth = d
+		return nil
+	}
+}
+
+// WithSort sets Sort.
+func WithSort(m SortMode) Option {
+	return func(o *Options) error {
+		o.Sort = m
+		return nil
+	}
+}
+
+// WithFormat sets Format, the allowed release forms.
+func WithFormat(f Format) Option {
+	return func(o *Options) error {
+		o.Format = f
+		return nil
+	}
+}
+
+// ReleaseOnly keeps releases of any form (X, X.Y, X.Y.Z), dropping
+// prereleases and non-semver tags: Format FormatAll.
+func ReleaseOnly() Option {
+	return WithFormat(FormatAll)
+}
+
+// PrereleaseOnly sets PrereleaseOnly and clears Format.
+func PrereleaseOnly() Option {
+	return func(o *Options) error {
+		o.PrereleaseOnly = true
+		o.Format = FormatNone
+		return nil
+	}
+}
+
+// WithLimit sets Limit and Offset.
+func WithLimit(limit, offset int) Option {
+	return func(o *Options) error {
+		o.Limit, o.Offset = limit, offset
+		return nil
+	}
+}
+
+// WithDedup enables Deduplicate keeping the alias chosen by prefer.
+func WithDedup(prefer DedupPrefer) Option {
+	return func(o *Options) error {
+		o.Deduplicate = true
+		o.DedupPrefer = prefer
+		return nil
+	}
+}
+
+// WithVPrefix sets VPrefix.
+func WithVPrefix(p VPrefix) Option {
+	return func(o *Options) error {
+		o.VPrefix = p
+		return nil
+	}
+}
+
+// WithRange sets Range to the inclusive [lo, hi]; empty means unbounded.
+// Unparseable bounds are errors.
+func WithRange(lo, hi string) Option {
+	return func(o *Options) error {
+		o.Range = Range{Min: lo, Max: hi}
+		return o.Range.validate(0)
+	}
+}
+
+// WithConstraint sets Constraint; an invalid expression is an error.
+func WithConstraint(expr string) Option {
+	return func(o *Options) error {
+		o.Constraint = expr
+		_, err := ParseConstraint(expr)
+		return err
+	}
+}
+
+// WithIncludePattern compiles pattern into Include, replacing an earlier one.
+func WithIncludePattern(pattern string) Option {
+	return func(o *Options) error {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+
+		o.Include = re
+		return nil
+	}
+}
+
+// WithExcludePattern compiles pattern into Exclude, replacing an earlier one.
+func WithExcludePattern(pattern string) Option {
+	return func(o *Options) error {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+
+		o.Exclude = re
+		return nil
+	}
+}
+
+// WithCanonicalOutput renders SemVer tags as vMAJOR.MINOR.PATCH[-PRERELEASE].
+func WithCanonicalOutput() Option {
+	return func(o *Options) error {
+		o.OutputCanonical = true
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package rats
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	opt, err := New(
+		WithDefaults(),
+		WithDepth(DepthPatch),
+		ReleaseOnly(),
+		WithRange("1.0", "2.0"),
+		WithIncludePattern(`^v?1`),
+		WithLimit(2, 0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := DefaultOptions()
+	want.Depth = DepthPatch
+	want.Range = Range{Min: "1.0", Max: "2.0"}
+	want.Include = regexp.MustCompile(`^v?1`)
+	want.Limit = 2
+	if !reflect.DeepEqual(opt.Config(), want.Config()) {
+		t.Fatalf("got %+v\nwant %+v", opt.Config(), want.Config())
+	}
+
+	in := []string{"v1.0.0", "v1.1.0-rc.1", "v1.1.0", "1.2.0", "v2.0.0", "latest"}
+	eqStrings(t, Select(in, opt), []string{"1.2.0", "v1.1.0"})
+}
+
+func TestNew_Errors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string][]Option{
+		"error parsing regexp": {WithIncludePattern("("), WithExcludePattern("[")},
+		`invalid max "x"`:      {WithRange("1", "x")},
+		"constraint":           {WithConstraint("^^1")},
+		"PrereleaseOnly":       {PrereleaseOnly(), ReleaseOnly()},
+	}
+	for want, opts := range cases {
+		if _, err := New(opts...); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("got %v, want %q", err, want)
+		}
+	}
+
+	// both pattern errors are reported
+	_, err := New(WithIncludePattern("("), WithExcludePattern("["))
+	if n := strings.Count(err.Error(), "error parsing regexp"); n != 2 {
+		t.Errorf("got %d errors: %v", n, err)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/woozymasta/rats"
+)
+
+// Exit codes of the cmp command.
+const (
+	exitCmpEqual = 0
+	exitCmpOlder = 10
+	exitCmpNewer = 11
+)
+
+// CmpCommand compares two tags by SemVer precedence.
+type CmpCommand struct {
+	Args struct {
+		A string `positional-arg-name:"A" description:"First tag"`
+		B string `positional-arg-name:"B" description:"Second tag"`
+	} `positional-args:"yes" required:"yes"`
+
+	Words bool `short:"w" long:"words" description:"Print older/equal/newer instead of -1/0/1"`
+}
+
+const cmpLongDescription = `Compare two tags by SemVer precedence (prereleases included, build ignored).
+Prints -1, 0 or 1 (A older, equal or newer than B) and exits with
+0 when equal, 10 when A is older, 11 when A is newer and 2 on invalid input.`
+
+// run prints the relation of A to B and exits with the matching code.
+func (c *CmpCommand) run() {
+	res, err := rats.Compare(c.Args.A, c.Args.B)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cmp: %v", err)
+		os.Exit(2)
+	}
+
+	words := map[int]string{-1: "older", 0: "equal", 1: "newer"}
+	codes := map[int]int{-1: exitCmpOlder, 0: exitCmpEqual, 1: exitCmpNewer}
+
+	if c.Words {
+		fmt.Println(words[res])
+	} else {
+		fmt.Println(res)
+	}
+
+	os.Exit(codes[res])
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/woozymasta/rats"
+)
+
+// loadConfig reads a --config file: the JSON form of the library Options
+// (rats.OptionsConfig), e.g. a profile written by json.Marshal(opts):
+//
+//	{
+//	  "filterSemver": true,
+//	  "format": "xyz",
+//	  "depth": "minor",
+//	  "sort": "desc",
+//	  "includeAny": ["^v?1\\."],
+//	  "range": {"min": "1.2", "max": "2"}
+//	}
+//
+// Unknown keys, invalid regexps and unknown tokens are errors.
+func loadConfig(path string) (rats.Options, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rats.Options{}, err
+	}
+
+	var opt rats.Options
+	if err := json.Unmarshal(data, &opt); err != nil {
+		return rats.Options{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return opt, nil
+}
+
+// mergeConfig returns cfg with the value of every flag given on the command
+// line taken from fl, the options built from the flags; an explicit flag
+// always wins. Runtime inputs (Ignore) always come from fl.
+func mergeConfig(cfg, fl rats.Options, p *flags.Parser) rats.Options {
+	given := func(long string) bool {
+		o := p.FindOptionByLongName(long)
+		return o != nil && o.IsSet() && !o.IsSetDefault()
+	}
+
+	if given("semver") {
+		cfg.FilterSemver = fl.FilterSemver
+	}
+	if given("deduplicate") {
+		cfg.Deduplicate = fl.Deduplicate
+	}
+	if given("prerelease-only") {
+		cfg.PrereleaseOnly = fl.PrereleaseOnly
+	}
+
+	if given("exclude-sigs") {
+		cfg.ExcludeSignatures = fl.ExcludeSignatures
+	}
+	if given("drop-digests") {
+		cfg.DropDigestLike = fl.DropDigestLike
+	}
+	if given("v-prefix") {
+		cfg.VPrefix = fl.VPrefix
+	}
+	if given("include") {
+		cfg.IncludeAny = fl.IncludeAny
+	}
+	if given("exclude") {
+		cfg.ExcludeAny = fl.ExcludeAny
+	}
+	if given("include-glob") {
+		cfg.IncludeGlob = fl.IncludeGlob
+	}
+	if given("exclude-glob") {
+		cfg.ExcludeGlob = fl.ExcludeGlob
+	}
+
+	if given("canonical-out") {
+		cfg.OutputCanonical = fl.OutputCanonical
+	}
+	if given("semver-out") {
+		cfg.OutputSemVer = fl.OutputSemVer
+	}
+
+	if given("limit") {
+		cfg.Limit = fl.Limit
+	}
+	if given("offset") {
+		cfg.Offset = fl.Offset
+	}
+	if given("keep-per-group") {
+		cfg.KeepPerGroup = fl.KeepPerGroup
+	}
+	if given("from-end") {
+		cfg.LimitFromEnd = fl.LimitFromEnd
+	}
+	if given("depth") {
+		cfg.Depth = fl.Depth
+	}
+	if given("sort") {
+		cfg.Sort = fl.Sort
+	}
+	if given("format") {
+		cfg.Format = fl.Format
+	}
+
+	if given("min") {
+		cfg.Range.Min = fl.Range.Min
+	}
+	if given("max") {
+		cfg.Range.Max = fl.Range.Max
+	}
+	if given("min-exclusive") {
+		cfg.Range.MinExclusive = fl.Range.MinExclusive
+	}
+	if given("max-exclusive") {
+		cfg.Range.MaxExclusive = fl.Range.MaxExclusive
+	}
+	if given("include-prerelease") {
+		cfg.Range.IncludePrerelease = fl.Range.IncludePrerelease
+	}
+	if given("exclude-prerelease-max") {
+		cfg.Range.ExcludePrereleaseAtMax = fl.Range.ExcludePrereleaseAtMax
+	}
+	if given("constraint") {
+		cfg.Constraint = fl.Constraint
+	}
+
+	cfg.Ignore = fl.Ignore
+	return cfg
+}
@@ -5,7 +5,6 @@ provides filtering, aggregation, and sorting utilities for container image tags.
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"regexp"
@@ -19,6 +18,12 @@ import (
 type Options struct {
 	// betteralign:ignore
 
+	// JSON file with default option values, flags override it
+	Config string `long:"config" description:"Read option values from a JSON file (flags given on the command line win)"`
+
+	// Print version and build info, then exit
+	Version bool `long:"version" description:"Print version and build info and exit"`
+
 	// SemVer & release behavior
 	OptionsSemver OptionsSemver `group:"SemVer and releases"`
 	// Aggregation and sorting
@@ -32,27 +37,45 @@ type Options struct {
 }
 
 type OptionsSemver struct {
-	FilterSemver bool `short:"s" long:"semver"       description:"Keep only SemVer tags (X.Y.Z[-pre][+build])"`
-	Deduplicate  bool `short:"d" long:"deduplicate"  description:"Collapse aliases of the same version (MAJOR.MINOR.PATCH+PRERELEASE)"`
+	FilterSemver       bool `short:"s" long:"semver"               description:"Keep only SemVer tags (X.Y.Z[-pre][+build])"`
+	Deduplicate        bool `short:"d" long:"deduplicate"          description:"Collapse aliases of the same version (MAJOR.MINOR.PATCH+PRERELEASE)"`
+	NoPrereleaseLatest bool `long:"no-prerelease-latest"           description:"Exit with code 3 when the latest version (prereleases included) is a prerelease"`
+	PrereleaseOnly     bool `long:"prerelease-only"                description:"Keep only SemVer tags with a prerelease (not with --format)"`
 }
 
 type OptionsOutput struct {
-	Canonical bool `short:"c" long:"canonical-out" description:"Print canonical vMAJOR.MINOR.PATCH[-PRERELEASE] (drop +BUILD)"`
-	SemVer    bool `short:"v" long:"semver-out"    description:"Print SemVer MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]"`
+	Format    string `short:"o" long:"output"        description:"Output format" choice:"lines" choice:"env" default:"lines"`
+	EnvPrefix string `long:"env-prefix"              description:"Variable name prefix for --output=env" default:"RATS_"`
+	Canonical bool   `short:"c" long:"canonical-out" description:"Print canonical vMAJOR.MINOR.PATCH[-PRERELEASE] (drop +BUILD)"`
+	SemVer    bool   `short:"v" long:"semver-out"    description:"Print SemVer MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]"`
+	Null      bool   `short:"0" long:"null"          description:"Read and write NUL-separated tags instead of lines"`
+	MaxLine   int    `long:"max-line"                description:"Longest accepted input tag in bytes (<=0 = unlimited)" default:"10485760"`
+	JSON      bool   `short:"j" long:"json"          description:"Print a JSON array of selected tags with parsed fields (rendered follows -c/-v)"`
+	Invert    bool   `short:"r" long:"invert"        description:"Print the input tags that would NOT be selected, in input order"`
+	Count     bool   `short:"C" long:"count"         description:"Print only the number of tags that would be printed"`
+	Explain   bool   `long:"explain"                 description:"Also print KEEP or DROP and the deciding stage of every input tag to stderr"`
+	GroupBy   string `long:"group-by"                description:"Print a '## X.x' or '## X.Y.x' header before each series (SemVer tags only)" choice:"major" choice:"minor"`
 }
 
 type OptionsAggregate struct {
-	FilterDepth   string `short:"D" long:"depth"    description:"Aggregation depth" choice:"none" choice:"patch" choice:"minor" choice:"major" choice:"latest" default:"none"`
-	SortMode      string `short:"S" long:"sort"     description:"Sort output tags" choice:"none" choice:"asc" choice:"desc" default:"none"`
+	FilterDepth   string `short:"D" long:"depth"    description:"Aggregation depth" choice:"none" choice:"patch" choice:"minor" choice:"major" choice:"latest" choice:"major-channels" default:"none"`
+	SortMode      string `short:"S" long:"sort"     description:"Sort output tags" choice:"none" choice:"asc" choice:"desc" choice:"reverse" choice:"natural" default:"none"`
 	ReleaseFormat string `short:"f" long:"format"   description:"Allowed release forms" choice:"x" choice:"xy" choice:"xyz" choice:"x-xy" choice:"x-xyz" choice:"xy-xyz" choice:"any" choice:"none" default:"none"`
 	Limit         int    `short:"n" long:"limit"    description:"Max number of output tags (<=0 = unlimited)" default:"0"`
+	KeepPerGroup  int    `long:"keep-per-group"     description:"Keep N newest versions per --depth group instead of one" default:"1"`
+	Offset        int    `long:"offset"             description:"Skip the first N output tags" default:"0"`
+	FromEnd       bool   `long:"from-end"           description:"Take --limit tags from the end instead of the start"`
 }
 
 type OptionsFilter struct {
-	VPrefixMode string `short:"V" long:"v-prefix"     description:"Policy for leading 'v' in tags" choice:"any" choice:"v" choice:"none" default:"any"`
-	Include     string `short:"i" long:"include"      description:"Regexp to keep tags (applied before parsing)"`
-	Exclude     string `short:"e" long:"exclude"      description:"Regexp to drop tags (applied before parsing)"`
-	ExcludeSigs bool   `short:"E" long:"exclude-sigs" description:"Drop sha256-<64>.sig tags"`
+	VPrefixMode string   `short:"V" long:"v-prefix"     description:"Policy for leading 'v' in tags" choice:"any" choice:"v" choice:"none" default:"any"`
+	Include     []string `short:"i" long:"include"      description:"Regexp to keep tags, applied before parsing (repeatable, any may match)"`
+	Exclude     []string `short:"e" long:"exclude"      description:"Regexp to drop tags, applied before parsing (repeatable)"`
+	IncludeGlob []string `long:"include-glob"           description:"Shell glob to keep tags, whole tag (repeatable, ANDed with --include)"`
+	ExcludeGlob []string `long:"exclude-glob"           description:"Shell glob to drop tags, whole tag (repeatable)"`
+	ExcludeSigs bool     `short:"E" long:"exclude-sigs" description:"Drop sha256-<64>/sha512-<128> hex .sig tags"`
+	DropDigests bool     `long:"drop-digests"           description:"Drop commit/digest-like lowercase hex tags (e.g. abc1234)"`
+	IgnoreFile  string   `long:"ignore-file"            description:"Read .ratsignore-style drop rules from file (default: ./.ratsignore if present)"`
 }
 
 type OptionsRange struct {
@@ -61,6 +84,8 @@ type OptionsRange struct {
 	MinExclusive    bool   `short:"M" long:"min-exclusive"      description:"Exclude lower bound itself"`
 	MaxExclusive    bool   `short:"X" long:"max-exclusive"      description:"Exclude upper bound itself"`
 	IncludePreAtMin bool   `short:"p" long:"include-prerelease" description:"When min is shorthand, include prereleases at the floor (>= X.Y.0-0)"`
+	ExcludePreAtMax bool   `long:"exclude-prerelease-max"       description:"Drop prereleases of a release max (1.2.3-rc.1 for --max 1.2.3)"`
+	Constraint      string `long:"constraint"                   description:"Constraint expression instead of min/max (e.g. '^1.2 || >=3')"`
 }
 
 func main() {
@@ -69,26 +94,52 @@ func main() {
 	parser.LongDescription = `RATS — Release App Tag Selector.
 A CLI tool for selecting versions from tag lists:
 supports SemVer and Go canonical (v-prefixed), can filter prereleases, drop build metadata, sort and aggregate results.`
-	if _, err := parser.Parse(); err != nil {
+	parser.SubcommandsOptional = true
+	parser.Usage = "[OPTIONS] [FILE...]"
+
+	var cmp CmpCommand
+	if _, err := parser.AddCommand("cmp", "Compare two tags", cmpLongDescription, &cmp); err != nil {
+		fmt.Fprintf(os.Stderr, "cmp command: %v", err)
+		os.Exit(2)
+	}
+
+	args, err := parser.Parse()
+	if err != nil {
 		if flagErr, ok := err.(*flags.Error); ok && flagErr.Type == flags.ErrHelp {
 			os.Exit(0)
 		}
 		os.Exit(1)
 	}
 
-	// Читаем stdin построчно, игнорируем пустые
-	in := make([]string, 0, 1024)
-	sc := bufio.NewScanner(os.Stdin)
-	const maxLine = 10 * 1024 * 1024
-	buf := make([]byte, 0, 64*1024)
-	sc.Buffer(buf, maxLine)
-	for sc.Scan() {
-		if s := strings.TrimSpace(sc.Text()); s != "" {
-			in = append(in, s)
+	if opt.Version {
+		if err := writeVersion(os.Stdout); err != nil {
+			os.Exit(2)
+		}
+		os.Exit(0)
+	}
+
+	if parser.Active != nil && parser.Active.Name == "cmp" {
+		cmp.run()
+	}
+
+	var cfg *rats.Options
+	if path := strings.TrimSpace(opt.Config); path != "" {
+		c, err := loadConfig(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "config: %v", err)
+			os.Exit(2)
 		}
+		cfg = &c
 	}
-	if err := sc.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "read stdin: %v", err)
+
+	// Читаем файлы из аргументов или stdin построчно (или по \0 с -0), игнорируем пустые
+	sep := byte('\n')
+	if opt.OptionsOutput.Null {
+		sep = 0
+	}
+	in, err := readInputs(args, sep, opt.OptionsOutput.MaxLine)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v", err)
 		os.Exit(2)
 	}
 
@@ -96,24 +147,44 @@ supports SemVer and Go canonical (v-prefixed), can filter prereleases, drop buil
 		fmt.Fprintf(os.Stderr, "--canonical-out and --semver-out are mutually exclusive")
 		os.Exit(2)
 	}
+	if opt.OptionsOutput.JSON && opt.OptionsOutput.Format == "env" {
+		fmt.Fprintf(os.Stderr, "--json and --output=env are mutually exclusive")
+		os.Exit(2)
+	}
+	if opt.OptionsOutput.Count && (opt.OptionsOutput.JSON || opt.OptionsOutput.Format == "env") {
+		fmt.Fprintf(os.Stderr, "--count cannot be combined with --json or --output=env")
+		os.Exit(2)
+	}
+	if opt.OptionsOutput.GroupBy != "" && (opt.OptionsOutput.JSON || opt.OptionsOutput.Format == "env" ||
+		opt.OptionsOutput.Count || opt.OptionsOutput.Invert) {
+		fmt.Fprintf(os.Stderr, "--group-by works only with plain line output")
+		os.Exit(2)
+	}
+	if opt.OptionsOutput.JSON && opt.OptionsOutput.Invert {
+		fmt.Fprintf(os.Stderr, "--json and --invert are mutually exclusive")
+		os.Exit(2)
+	}
+	if opt.OptionsOutput.Invert && opt.OptionsOutput.Format == "env" {
+		fmt.Fprintf(os.Stderr, "--invert and --output=env are mutually exclusive")
+		os.Exit(2)
+	}
 
 	// Компилим regex (если заданы)
-	var incRe, excRe *regexp.Regexp
-	if s := strings.TrimSpace(opt.OptionsFilter.Include); s != "" {
-		re, err := regexp.Compile(s)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "include regexp: %v", err)
-			os.Exit(2)
-		}
-		incRe = re
+	incRe, err := compileRegexps(opt.OptionsFilter.Include)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "include regexp %v", err)
+		os.Exit(2)
 	}
-	if s := strings.TrimSpace(opt.OptionsFilter.Exclude); s != "" {
-		re, err := regexp.Compile(s)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "exclude regexp: %v", err)
-			os.Exit(2)
-		}
-		excRe = re
+	excRe, err := compileRegexps(opt.OptionsFilter.Exclude)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "exclude regexp %v", err)
+		os.Exit(2)
+	}
+
+	ignore, err := loadIgnore(opt.OptionsFilter.IgnoreFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ignore file: %v", err)
+		os.Exit(2)
 	}
 
 	// Стартуем с дефолтов и переопределяем флагами
@@ -121,30 +192,213 @@ supports SemVer and Go canonical (v-prefixed), can filter prereleases, drop buil
 
 	rOpt.FilterSemver = opt.OptionsSemver.FilterSemver
 	rOpt.Deduplicate = opt.OptionsSemver.Deduplicate
+	rOpt.PrereleaseOnly = opt.OptionsSemver.PrereleaseOnly
 
 	rOpt.ExcludeSignatures = opt.OptionsFilter.ExcludeSigs
+	rOpt.DropDigestLike = opt.OptionsFilter.DropDigests
 	rOpt.VPrefix = rats.ParseVPrefix(opt.OptionsFilter.VPrefixMode)
 
 	rOpt.OutputCanonical = opt.OptionsOutput.Canonical
 	rOpt.OutputSemVer = opt.OptionsOutput.SemVer
-	rOpt.Include = incRe
-	rOpt.Exclude = excRe
+	rOpt.IncludeAny = incRe
+	rOpt.ExcludeAny = excRe
+	rOpt.IncludeGlob = opt.OptionsFilter.IncludeGlob
+	rOpt.ExcludeGlob = opt.OptionsFilter.ExcludeGlob
+	rOpt.Ignore = ignore
 
 	rOpt.Limit = opt.OptionsAggregate.Limit
+	rOpt.Offset = opt.OptionsAggregate.Offset
+	rOpt.KeepPerGroup = opt.OptionsAggregate.KeepPerGroup
+	rOpt.LimitFromEnd = opt.OptionsAggregate.FromEnd
 	rOpt.Depth = rats.ParseDepth(opt.OptionsAggregate.FilterDepth)
 	rOpt.Sort = rats.ParseSort(opt.OptionsAggregate.SortMode)
 	rOpt.Format = rats.ParseFormat(opt.OptionsAggregate.ReleaseFormat)
 
 	rOpt.Range = rats.Range{
-		Min:               strings.TrimSpace(opt.OptionsRange.Min),
-		Max:               strings.TrimSpace(opt.OptionsRange.Max),
-		MinExclusive:      opt.OptionsRange.MinExclusive,
-		MaxExclusive:      opt.OptionsRange.MaxExclusive,
-		IncludePrerelease: opt.OptionsRange.IncludePreAtMin,
+		Min:                    strings.TrimSpace(opt.OptionsRange.Min),
+		Max:                    strings.TrimSpace(opt.OptionsRange.Max),
+		MinExclusive:           opt.OptionsRange.MinExclusive,
+		MaxExclusive:           opt.OptionsRange.MaxExclusive,
+		IncludePrerelease:      opt.OptionsRange.IncludePreAtMin,
+		ExcludePrereleaseAtMax: opt.OptionsRange.ExcludePreAtMax,
+	}
+
+	rOpt.Constraint = strings.TrimSpace(opt.OptionsRange.Constraint)
+
+	// профиль из --config, явно заданные флаги важнее
+	if cfg != nil {
+		rOpt = mergeConfig(*cfg, rOpt, parser)
 	}
 
-	out := rats.Select(in, rOpt)
-	for _, t := range out {
-		fmt.Println(t)
+	// противоречивые опции дают пустой вывод, падаем сразу
+	if err := rOpt.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "options: %v", err)
+		os.Exit(2)
+	}
+
+	var out []string
+	if opt.OptionsOutput.Invert {
+		out = invertTags(in, rOpt)
+	} else if out, err = rats.SelectErr(in, rOpt); err != nil {
+		fmt.Fprintf(os.Stderr, "select: %v", err)
+		os.Exit(2)
 	}
+
+	var werr error
+	switch {
+	case opt.OptionsOutput.Count:
+		_, werr = fmt.Fprintln(os.Stdout, len(out))
+	case opt.OptionsOutput.JSON:
+		werr = writeJSON(os.Stdout, in, rOpt)
+	case opt.OptionsOutput.GroupBy != "":
+		by := rats.ParseDepth(opt.OptionsOutput.GroupBy)
+		werr = writeGroups(os.Stdout, rats.SelectGrouped(in, by, rOpt), sep)
+	case opt.OptionsOutput.Format == "env":
+		werr = writeEnv(os.Stdout, opt.OptionsOutput.EnvPrefix, out)
+	default:
+		werr = writeLines(os.Stdout, out, sep)
+	}
+	if werr != nil {
+		fmt.Fprintf(os.Stderr, "write output: %v", werr)
+		os.Exit(2)
+	}
+
+	// разбор решений только по запросу, основной путь не трогаем
+	if opt.OptionsOutput.Explain {
+		if err := writeExplain(os.Stderr, rats.Explain(in, rOpt)); err != nil {
+			fmt.Fprintf(os.Stderr, "write explain: %v", err)
+			os.Exit(2)
+		}
+	}
+
+	if opt.OptionsSemver.NoPrereleaseLatest {
+		if latest, ok := latestIsPrerelease(in, rOpt); ok {
+			fmt.Fprintf(os.Stderr, "latest version %s is a prerelease", latest)
+			os.Exit(exitPrereleaseLatest)
+		}
+	}
+}
+
+// invertTags returns the inputs whose raw form is not selected by opt, in input
+// order. With aggregation (--depth, --limit, ...) every tag that lost to
+// another one is printed; repeated lines of a selected tag are not. Kept
+// tags come from the per-input decisions of rats.Explain, so rewrites of the
+// output (NormalizeAggregatedPrefix, ...) do not matter.
+func invertTags(in []string, opt rats.Options) []string {
+	kept := make(map[string]struct{}, len(in))
+	for _, d := range rats.Explain(in, opt) {
+		if d.Kept {
+			kept[d.Raw] = struct{}{}
+		}
+	}
+
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if _, ok := kept[s]; !ok {
+			out = append(out, s)
+		}
+	}
+
+	return out
+}
+
+// exitPrereleaseLatest is the exit code of --no-prerelease-latest.
+const exitPrereleaseLatest = 3
+
+// latestIsPrerelease finds the single latest SemVer tag with prereleases
+// included (input filters and range still apply) and reports it when it is a prerelease.
+func latestIsPrerelease(in []string, opt rats.Options) (string, bool) {
+	opt.Format = rats.FormatNone
+	opt.FilterSemver = true
+	opt.Depth = rats.DepthLatest
+	opt.Limit = 0
+
+	vs := rats.SelectParsed(in, opt)
+	if len(vs) == 0 || !vs[0].HasPre() {
+		return "", false
+	}
+
+	return vs[0].Original, true
+}
+
+// compileRegexps compiles non-blank patterns, reporting the 1-based index
+// of the first invalid one.
+func compileRegexps(patterns []string) ([]*regexp.Regexp, error) {
+	var out []*regexp.Regexp
+	for i, p := range patterns {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("#%d: %w", i+1, err)
+		}
+		out = append(out, re)
+	}
+
+	return out, nil
+}
+
+// readInputs concatenates the tags of every file in paths ("-" is stdin),
+// or reads stdin when paths is empty. Tags are at most maxLine bytes
+// (<= 0 = unlimited).
+func readInputs(paths []string, sep byte, maxLine int) ([]string, error) {
+	if len(paths) == 0 {
+		in, err := rats.ScanTagsLimit(os.Stdin, sep, maxLine)
+		if err != nil {
+			return nil, fmt.Errorf("read stdin: %w", err)
+		}
+
+		return in, nil
+	}
+
+	var in []string
+	for _, p := range paths {
+		tags, err := readFile(p, sep, maxLine)
+		if err != nil {
+			return nil, err
+		}
+		in = append(in, tags...)
+	}
+
+	return in, nil
+}
+
+// readFile reads the tags of one input file ("-" is stdin).
+func readFile(path string, sep byte, maxLine int) ([]string, error) {
+	if path == "-" {
+		tags, err := rats.ScanTagsLimit(os.Stdin, sep, maxLine)
+		if err != nil {
+			return nil, fmt.Errorf("read stdin: %w", err)
+		}
+
+		return tags, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open input: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	tags, err := rats.ScanTagsLimit(f, sep, maxLine)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	return tags, nil
+}
+
+// loadIgnore reads the explicit ignore file, or ./.ratsignore when it exists.
+func loadIgnore(path string) (*rats.IgnoreList, error) {
+	if path = strings.TrimSpace(path); path != "" {
+		return rats.LoadIgnore(path)
+	}
+
+	if st, err := os.Stat(rats.IgnoreFileName); err != nil || st.IsDir() {
+		return nil, nil //nolint:nilerr // a missing default ignore file is not an error
+	}
+
+	return rats.LoadIgnore(rats.IgnoreFileName)
 }
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/woozymasta/rats"
+	"github.com/woozymasta/semver"
+)
+
+// writeLines prints each tag followed by sep ('\n' or 0).
+func writeLines(w io.Writer, tags []string, sep byte) error {
+	bw := bufio.NewWriter(w)
+	for _, t := range tags {
+		if _, err := bw.WriteString(t); err != nil {
+			return err
+		}
+		if err := bw.WriteByte(sep); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// writeGroups prints a header per group followed by its tags, each ended by sep:
+//
+//	## 2.x        (or "## 2.1.x" for minor groups)
+//	2.1.0
+//	2.0.3
+//
+// Groups and tags keep the rats.SelectGrouped order.
+func writeGroups(w io.Writer, groups []rats.Group, sep byte) error {
+	lines := make([]string, 0, 2*len(groups))
+	for _, g := range groups {
+		lines = append(lines, "## "+g.Key+".x")
+		lines = append(lines, g.Tags...)
+	}
+
+	return writeLines(w, lines, sep)
+}
+
+// writeJSON prints the selection as a JSON array (see rats.SelectJSON).
+func writeJSON(w io.Writer, in []string, opt rats.Options) error {
+	data, err := rats.SelectJSON(in, opt)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+
+	return err
+}
+
+// writeExplain prints a tab-separated line per input tag, in input order:
+//
+//	KEEP	v1.2.3
+//	DROP	v1.2.3-rc.1	prerelease
+func writeExplain(w io.Writer, ds []rats.TagDecision) error {
+	bw := bufio.NewWriter(w)
+	for _, d := range ds {
+		var err error
+		if d.Kept {
+			_, err = fmt.Fprintf(bw, "KEEP\t%s\n", d.Raw)
+		} else {
+			_, err = fmt.Fprintf(bw, "DROP\t%s\t%s\n", d.Raw, d.Reason)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// writeEnv prints shell-sourceable assignments:
+//
+//	<PREFIX>LATEST=2.1.0
+//	<PREFIX>VERSIONS="2.1.0 2.0.3"
+//
+// LATEST is the greatest SemVer tag of the selection (the first tag when none parse),
+// VERSIONS keeps the selection order. An empty selection yields empty assignments.
+func writeEnv(w io.Writer, prefix string, tags []string) error {
+	if !isEnvName(prefix + "LATEST") {
+		return fmt.Errorf("invalid env prefix %q", prefix)
+	}
+
+	_, err := fmt.Fprintf(w, "%sLATEST=%s\n%sVERSIONS=\"%s\"\n",
+		prefix, shellValue(latestTag(tags)),
+		prefix, shellEscape(strings.Join(tags, " ")))
+
+	return err
+}
+
+// latestTag returns the greatest SemVer tag, or the first tag if none parse.
+func latestTag(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	best, bestV, found := tags[0], semver.Semver{}, false
+	for _, t := range tags {
+		v, ok := semver.Parse(t)
+		if !ok || !v.Valid {
+			continue
+		}
+		if !found || v.Compare(bestV) > 0 {
+			best, bestV, found = t, v, true
+		}
+	}
+
+	return best
+}
+
+// isEnvName reports whether s is a valid POSIX shell variable name.
+func isEnvName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// shellValue returns s as is when it is a plain word, double-quoted otherwise.
+func shellValue(s string) string {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		plain := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			c == '.' || c == '-' || c == '+' || c == '_'
+		if !plain {
+			return `"` + shellEscape(s) + `"`
+		}
+	}
+
+	return s
+}
+
+// shellEscape escapes characters special inside double quotes.
+func shellEscape(s string) string {
+	if !strings.ContainsAny(s, "\"\\$`") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\\', '$', '`':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+)
+
+// version may be set at build time with -ldflags "-X main.version=v1.2.3";
+// otherwise the module version from the build info is reported.
+var version string
+
+// writeVersion prints the tool version and the VCS state it was built from:
+//
+//	rats v0.5.0 (a1b2c3d4e5f6, 2025-01-02T03:04:05Z, modified) go1.24.0
+func writeVersion(w io.Writer) error {
+	v, vcs := version, ""
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if v == "" {
+			v = bi.Main.Version
+		}
+
+		var rev, at, dirty string
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				rev = s.Value
+				if len(rev) > 12 {
+					rev = rev[:12]
+				}
+			case "vcs.time":
+				at = s.Value
+			case "vcs.modified":
+				if s.Value == "true" {
+					dirty = ", modified"
+				}
+			}
+		}
+		if rev != "" {
+			vcs = fmt.Sprintf(" (%s, %s%s)", rev, at, dirty)
+		}
+	}
+	if v == "" {
+		v = "(devel)"
+	}
+
+	_, err := fmt.Fprintf(w, "rats %s%s %s\n", v, vcs, runtime.Version())
+
+	return err
+}
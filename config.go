@@ -0,0 +1,400 @@
+package rats
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// OptionsConfig is the serializable form of Options, e.g. a selection profile
+// kept in config management. Regexps are pattern strings, enums their String
+// tokens ("minor", "descending", "x-xy") and MinAge a time.Duration string.
+// Any Parse* alias is accepted back; zero values are omitted.
+//
+// Runtime inputs (Ignore, Timestamps, Now) have no serialized form.
+type OptionsConfig struct {
+	Include         string   `json:"include,omitempty"`
+	Exclude         string   `json:"exclude,omitempty"`
+	IncludeAny      []string `json:"includeAny,omitempty"`
+	ExcludeAny      []string `json:"excludeAny,omitempty"`
+	MatchCanonical  bool     `json:"matchCanonical,omitempty"`
+	IncludeSuffixes []string `json:"includeSuffixes,omitempty"`
+	ExcludeSuffixes []string `json:"excludeSuffixes,omitempty"`
+	ExcludePrefixes []string `json:"excludePrefixes,omitempty"`
+	AffixIgnoreCase bool     `json:"affixIgnoreCase,omitempty"`
+	CaseInsensitive bool     `json:"caseInsensitive,omitempty"`
+	IncludeGlob     []string `json:"includeGlob,omitempty"`
+	ExcludeGlob     []string `json:"excludeGlob,omitempty"`
+
+	Range      *Range  `json:"range,omitempty"`
+	Ranges     []Range `json:"ranges,omitempty"`
+	Constraint string  `json:"constraint,omitempty"`
+
+	Limit             int  `json:"limit,omitempty"`
+	Offset            int  `json:"offset,omitempty"`
+	LimitFromEnd      bool `json:"limitFromEnd,omitempty"`
+	LimitSpreadMajors bool `json:"limitSpreadMajors,omitempty"`
+
+	Depth              string `json:"depth,omitempty"`
+	KeepPerGroup       int    `json:"keepPerGroup,omitempty"`
+	FallbackPrerelease bool   `json:"fallbackPrerelease,omitempty"`
+	SameMajor          bool   `json:"sameMajor,omitempty"`
+
+	FilterSemver       bool     `json:"filterSemver,omitempty"`
+	NonSemverPlacement string   `json:"nonSemverPlacement,omitempty"`
+	FloatingTags       []string `json:"floatingTags,omitempty"`
+	Deduplicate        bool     `json:"deduplicate,omitempty"`
+	DedupPrefer        string   `json:"dedupPrefer,omitempty"`
+	DedupByMinor       bool     `json:"dedupByMinor,omitempty"`
+	DedupOutput        bool     `json:"dedupOutput,omitempty"`
+
+	OutputCanonical          bool   `json:"outputCanonical,omitempty"`
+	OutputCanonicalWithBuild bool   `json:"outputCanonicalWithBuild,omitempty"`
+	OutputSemVer             bool   `json:"outputSemVer,omitempty"`
+	OutputStripV             bool   `json:"outputStripV,omitempty"`
+	OutputAddV               bool   `json:"outputAddV,omitempty"`
+	OutputTemplate           string `json:"outputTemplate,omitempty"`
+
+	ReleaseQualifiers          []string `json:"releaseQualifiers,omitempty"`
+	NormalizePrereleaseNumbers bool     `json:"normalizePrereleaseNumbers,omitempty"`
+	EpochAware                 bool     `json:"epochAware,omitempty"`
+	CalVer                     bool     `json:"calVer,omitempty"`
+	RequireFullVersion         bool     `json:"requireFullVersion,omitempty"`
+	ExactComponents            int      `json:"exactComponents,omitempty"`
+
+	ExcludeSignatures bool     `json:"excludeSignatures,omitempty"`
+	SignatureSuffixes []string `json:"signatureSuffixes,omitempty"`
+	DropDigestLike    bool     `json:"dropDigestLike,omitempty"`
+	DigestLikeMinLen  int      `json:"digestLikeMinLen,omitempty"`
+
+	MinAge      string `json:"minAge,omitempty"`
+	DropUndated bool   `json:"dropUndated,omitempty"`
+
+	Format              string   `json:"format,omitempty"`
+	AllowBuildInRelease bool     `json:"allowBuildInRelease,omitempty"`
+	PrereleaseOnly      bool     `json:"prereleaseOnly,omitempty"`
+	PrereleaseChannels  []string `json:"prereleaseChannels,omitempty"`
+
+	Sort            string `json:"sort,omitempty"`
+	TieBreak        string `json:"tieBreak,omitempty"`
+	CompareBuild    bool   `json:"compareBuild,omitempty"`
+	WithinGroupSort string `json:"withinGroupSort,omitempty"`
+
+	VPrefix                   string `json:"vPrefix,omitempty"`
+	NormalizeAggregatedPrefix string `json:"normalizeAggregatedPrefix,omitempty"`
+
+	Parallelism int `json:"parallelism,omitempty"`
+}
+
+// Config returns the serializable form of o.
+func (o Options) Config() OptionsConfig {
+	c := OptionsConfig{
+		Include:         reString(o.Include),
+		Exclude:         reString(o.Exclude),
+		IncludeAny:      reStrings(o.IncludeAny),
+		ExcludeAny:      reStrings(o.ExcludeAny),
+		MatchCanonical:  o.MatchCanonical,
+		IncludeSuffixes: o.IncludeSuffixes,
+		ExcludeSuffixes: o.ExcludeSuffixes,
+		ExcludePrefixes: o.ExcludePrefixes,
+		AffixIgnoreCase: o.AffixIgnoreCase,
+		CaseInsensitive: o.CaseInsensitive,
+		IncludeGlob:     o.IncludeGlob,
+		ExcludeGlob:     o.ExcludeGlob,
+
+		Ranges:     o.Ranges,
+		Constraint: o.Constraint,
+
+		Limit:             o.Limit,
+		Offset:            o.Offset,
+		LimitFromEnd:      o.LimitFromEnd,
+		LimitSpreadMajors: o.LimitSpreadMajors,
+
+		KeepPerGroup:       o.KeepPerGroup,
+		FallbackPrerelease: o.FallbackPrerelease,
+		SameMajor:          o.SameMajor,
+
+		FilterSemver: o.FilterSemver,
+		FloatingTags: o.FloatingTags,
+		Deduplicate:  o.Deduplicate,
+		DedupByMinor: o.DedupByMinor,
+		DedupOutput:  o.DedupOutput,
+
+		OutputCanonical:          o.OutputCanonical,
+		OutputCanonicalWithBuild: o.OutputCanonicalWithBuild,
+		OutputSemVer:             o.OutputSemVer,
+		OutputStripV:             o.OutputStripV,
+		OutputAddV:               o.OutputAddV,
+		OutputTemplate:           o.OutputTemplate,
+
+		ReleaseQualifiers:          o.ReleaseQualifiers,
+		NormalizePrereleaseNumbers: o.NormalizePrereleaseNumbers,
+		EpochAware:                 o.EpochAware,
+		CalVer:                     o.CalVer,
+		RequireFullVersion:         o.RequireFullVersion,
+		ExactComponents:            o.ExactComponents,
+
+		ExcludeSignatures: o.ExcludeSignatures,
+		SignatureSuffixes: o.SignatureSuffixes,
+		DropDigestLike:    o.DropDigestLike,
+		DigestLikeMinLen:  o.DigestLikeMinLen,
+
+		DropUndated: o.DropUndated,
+
+		AllowBuildInRelease: o.AllowBuildInRelease,
+		PrereleaseOnly:      o.PrereleaseOnly,
+		PrereleaseChannels:  o.PrereleaseChannels,
+
+		CompareBuild: o.CompareBuild,
+
+		Parallelism: o.Parallelism,
+	}
+
+	if o.Range != (Range{}) {
+		r := o.Range
+		c.Range = &r
+	}
+
+	if o.MinAge != 0 {
+		c.MinAge = o.MinAge.String()
+	}
+
+	// zero enums stay empty
+	if o.Depth != DepthAny {
+		c.Depth = o.Depth.String()
+	}
+	if o.NonSemverPlacement != NonSemverAppend {
+		c.NonSemverPlacement = o.NonSemverPlacement.String()
+	}
+	if o.DedupPrefer != PreferFirstSeen {
+		c.DedupPrefer = o.DedupPrefer.String()
+	}
+	if o.Format != FormatNone {
+		c.Format = o.Format.String()
+	}
+	if o.Sort != SortNone {
+		c.Sort = o.Sort.String()
+	}
+	if o.TieBreak != TieLexical {
+		c.TieBreak = o.TieBreak.String()
+	}
+	if o.WithinGroupSort != SortNone {
+		c.WithinGroupSort = o.WithinGroupSort.String()
+	}
+	if o.VPrefix != PrefixAny {
+		c.VPrefix = o.VPrefix.String()
+	}
+	if o.NormalizeAggregatedPrefix != PrefixAny {
+		c.NormalizeAggregatedPrefix = o.NormalizeAggregatedPrefix.String()
+	}
+
+	return c
+}
+
+// Options compiles c. Invalid regexps, unknown enum tokens, a bad MinAge and
+// everything Options.Validate rejects are reported together.
+func (c OptionsConfig) Options() (Options, error) {
+	o := Options{
+		MatchCanonical:  c.MatchCanonical,
+		IncludeSuffixes: c.IncludeSuffixes,
+		ExcludeSuffixes: c.ExcludeSuffixes,
+		ExcludePrefixes: c.ExcludePrefixes,
+		AffixIgnoreCase: c.AffixIgnoreCase,
+		CaseInsensitive: c.CaseInsensitive,
+		IncludeGlob:     c.IncludeGlob,
+		ExcludeGlob:     c.ExcludeGlob,
+
+		Ranges:     c.Ranges,
+		Constraint: c.Constraint,
+
+		Limit:             c.Limit,
+		Offset:            c.Offset,
+		LimitFromEnd:      c.LimitFromEnd,
+		LimitSpreadMajors: c.LimitSpreadMajors,
+
+		KeepPerGroup:       c.KeepPerGroup,
+		FallbackPrerelease: c.FallbackPrerelease,
+		SameMajor:          c.SameMajor,
+
+		FilterSemver: c.FilterSemver,
+		FloatingTags: c.FloatingTags,
+		Deduplicate:  c.Deduplicate,
+		DedupByMinor: c.DedupByMinor,
+		DedupOutput:  c.DedupOutput,
+
+		OutputCanonical:          c.OutputCanonical,
+		OutputCanonicalWithBuild: c.OutputCanonicalWithBuild,
+		OutputSemVer:             c.OutputSemVer,
+		OutputStripV:             c.OutputStripV,
+		OutputAddV:               c.OutputAddV,
+		OutputTemplate:           c.OutputTemplate,
+
+		ReleaseQualifiers:          c.ReleaseQualifiers,
+		NormalizePrereleaseNumbers: c.NormalizePrereleaseNumbers,
+		EpochAware:                 c.EpochAware,
+		CalVer:                     c.CalVer,
+		RequireFullVersion:         c.RequireFullVersion,
+		ExactComponents:            c.ExactComponents,
+
+		ExcludeSignatures: c.ExcludeSignatures,
+		SignatureSuffixes: c.SignatureSuffixes,
+		DropDigestLike:    c.DropDigestLike,
+		DigestLikeMinLen:  c.DigestLikeMinLen,
+
+		DropUndated: c.DropUndated,
+
+		AllowBuildInRelease: c.AllowBuildInRelease,
+		PrereleaseOnly:      c.PrereleaseOnly,
+		PrereleaseChannels:  c.PrereleaseChannels,
+
+		CompareBuild: c.CompareBuild,
+
+		Parallelism: c.Parallelism,
+	}
+
+	if c.Range != nil {
+		o.Range = *c.Range
+	}
+
+	var errs []error
+	addErr := func(name string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	var err error
+	o.Include, err = compileRe(c.Include)
+	addErr("include", err)
+	o.Exclude, err = compileRe(c.Exclude)
+	addErr("exclude", err)
+	o.IncludeAny, err = compileRes(c.IncludeAny)
+	addErr("includeAny", err)
+	o.ExcludeAny, err = compileRes(c.ExcludeAny)
+	addErr("excludeAny", err)
+
+	if c.MinAge != "" {
+		o.MinAge, err = time.ParseDuration(c.MinAge)
+		addErr("minAge", err)
+	}
+
+	var ok bool
+	if o.Depth, ok = parseDepth(c.Depth); !ok && c.Depth != "" {
+		addErr("depth", unknownToken(c.Depth))
+	}
+	if o.NonSemverPlacement, ok = parseNonSemverPlacement(c.NonSemverPlacement); !ok {
+		addErr("nonSemverPlacement", unknownToken(c.NonSemverPlacement))
+	}
+	if o.DedupPrefer, ok = parseDedupPrefer(c.DedupPrefer); !ok {
+		addErr("dedupPrefer", unknownToken(c.DedupPrefer))
+	}
+	if o.Format, ok = parseFormat(c.Format); !ok {
+		addErr("format", unknownToken(c.Format))
+	}
+	if o.Sort, ok = parseSort(c.Sort); !ok && c.Sort != "" {
+		addErr("sort", unknownToken(c.Sort))
+	}
+	if o.TieBreak, ok = parseTieBreak(c.TieBreak); !ok {
+		addErr("tieBreak", unknownToken(c.TieBreak))
+	}
+	if o.WithinGroupSort, ok = parseSort(c.WithinGroupSort); !ok && c.WithinGroupSort != "" {
+		addErr("withinGroupSort", unknownToken(c.WithinGroupSort))
+	}
+	if o.VPrefix, ok = parseVPrefix(c.VPrefix); !ok {
+		addErr("vPrefix", unknownToken(c.VPrefix))
+	}
+	if o.NormalizeAggregatedPrefix, ok = parseVPrefix(c.NormalizeAggregatedPrefix); !ok {
+		addErr("normalizeAggregatedPrefix", unknownToken(c.NormalizeAggregatedPrefix))
+	}
+
+	errs = append(errs, o.Validate())
+
+	return o, errors.Join(errs...)
+}
+
+// MarshalJSON encodes o as its OptionsConfig.
+func (o Options) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Config())
+}
+
+// UnmarshalJSON decodes an OptionsConfig into o. Unknown keys are errors,
+// as is anything OptionsConfig.Options rejects; o is unchanged on error.
+func (o *Options) UnmarshalJSON(data []byte) error {
+	var c OptionsConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return err
+	}
+
+	opt, err := c.Options()
+	if err != nil {
+		return err
+	}
+
+	*o = opt
+	return nil
+}
+
+// unknownToken reports an enum value no Parse* alias matches.
+func unknownToken(s string) error {
+	return fmt.Errorf("unknown value %q", s)
+}
+
+// compileRe compiles a non-empty pattern.
+func compileRe(p string) (*regexp.Regexp, error) {
+	if p == "" {
+		return nil, nil
+	}
+
+	return regexp.Compile(p)
+}
+
+// compileRes compiles every pattern; errors carry the pattern index.
+func compileRes(ps []string) ([]*regexp.Regexp, error) {
+	if len(ps) == 0 {
+		return nil, nil
+	}
+
+	out := make([]*regexp.Regexp, len(ps))
+	var errs []error
+	for i, p := range ps {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("#%d: %w", i, err))
+			continue
+		}
+		out[i] = re
+	}
+
+	return out, errors.Join(errs...)
+}
+
+// reString returns the source of re, "" for nil.
+func reString(re *regexp.Regexp) string {
+	if re == nil {
+		return ""
+	}
+
+	return re.String()
+}
+
+// reStrings returns the sources of res. Nil entries (never matching) have no
+// pattern form and are dropped.
+func reStrings(res []*regexp.Regexp) []string {
+	if len(res) == 0 {
+		return nil
+	}
+
+	out := make([]string, 0, len(res))
+	for _, re := range res {
+		if re != nil {
+			out = append(out, re.String())
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,130 @@
+package rats
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnumStringParseSymmetry(t *testing.T) {
+	t.Parallel()
+
+	for _, d := range []Depth{DepthAny, DepthPatch, DepthMinor, DepthMajor, DepthLatest, DepthMajorChannels} {
+		if got, ok := parseDepth(d.String()); !ok || got != d {
+			t.Errorf("depth %q: got %v (%v)", d, got, ok)
+		}
+	}
+	for _, f := range []Format{FormatNone, FormatX, FormatXY, FormatXYZ, FormatX | FormatXY, FormatX | FormatXYZ, FormatXY | FormatXYZ, FormatAll} {
+		if got, ok := parseFormat(f.String()); !ok || got != f {
+			t.Errorf("format %q: got %v (%v)", f, got, ok)
+		}
+	}
+	for _, m := range []SortMode{SortNone, SortAsc, SortDesc, SortReverse, SortNatural} {
+		if got, ok := parseSort(m.String()); !ok || got != m {
+			t.Errorf("sort %q: got %v (%v)", m, got, ok)
+		}
+	}
+	for _, p := range []VPrefix{PrefixAny, PrefixV, PrefixNone} {
+		if got, ok := parseVPrefix(p.String()); !ok || got != p {
+			t.Errorf("vprefix %q: got %v (%v)", p, got, ok)
+		}
+	}
+	for _, p := range []NonSemverPlacement{NonSemverAppend, NonSemverPrepend, NonSemverDrop} {
+		if got, ok := parseNonSemverPlacement(p.String()); !ok || got != p {
+			t.Errorf("non-semver placement %q: got %v (%v)", p, got, ok)
+		}
+	}
+	for _, tb := range []TieBreak{TieLexical, TieShortest, TieInputOrder} {
+		if got, ok := parseTieBreak(tb.String()); !ok || got != tb {
+			t.Errorf("tiebreak %q: got %v (%v)", tb, got, ok)
+		}
+	}
+	for _, p := range []DedupPrefer{PreferFirstSeen, PreferCanonical, PreferHighestBuild, PreferShortest} {
+		if got, ok := parseDedupPrefer(p.String()); !ok || got != p {
+			t.Errorf("dedup prefer %q: got %v (%v)", p, got, ok)
+		}
+	}
+}
+
+func TestOptionsJSON_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	opt := Options{
+		Include:         regexp.MustCompile(`^v?1\.`),
+		ExcludeAny:      []*regexp.Regexp{regexp.MustCompile(`-alpine$`), regexp.MustCompile(`^nightly`)},
+		ExcludeSuffixes: []string{"-slim"},
+		Range:           Range{Min: "1.2", IncludePrerelease: true},
+		Limit:           3,
+		Depth:           DepthMinor,
+		FilterSemver:    true,
+		Deduplicate:     true,
+		DedupPrefer:     PreferCanonical,
+		Format:          FormatX | FormatXY,
+		Sort:            SortDesc,
+		TieBreak:        TieShortest,
+		VPrefix:         PrefixV,
+		MinAge:          72 * time.Hour,
+	}
+
+	data, err := json.Marshal(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"depth":"minor"`, `"sort":"descending"`, `"format":"x-xy"`, `"include":"^v?1\\.`, `"minAge":"72h0m0s"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("missing %s in %s", want, data)
+		}
+	}
+
+	var back Options
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Config(), opt.Config()) {
+		t.Fatalf("round trip:\n got  %+v\n want %+v", back.Config(), opt.Config())
+	}
+
+	in := []string{"v1.2.3", "v1.2.4-alpine", "v1.3", "v1.3.0", "v2.0.0", "1.4.0", "nightly-1"}
+	opt.MinAge, back.MinAge = 0, 0 // no timestamps here
+	eqStrings(t, Select(in, back), Select(in, opt))
+}
+
+func TestOptionsJSON_Aliases(t *testing.T) {
+	t.Parallel()
+
+	var opt Options
+	if err := json.Unmarshal([]byte(`{"depth":"xy","sort":"DESC","format":"major|minor","dedupPrefer":"canon"}`), &opt); err != nil {
+		t.Fatal(err)
+	}
+	if opt.Depth != DepthMinor || opt.Sort != SortDesc || opt.Format != FormatX|FormatXY || opt.DedupPrefer != PreferCanonical {
+		t.Fatalf("got %+v", opt.Config())
+	}
+}
+
+func TestOptionsJSON_Errors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		`{"include":"("}`:                         "include:",
+		`{"excludeAny":["ok","["]}`:               "excludeAny: #1:",
+		`{"depth":"minr"}`:                        `depth: unknown value "minr"`,
+		`{"format":"x-zz"}`:                       `format: unknown value "x-zz"`,
+		`{"sort":"sideways"}`:                     `sort: unknown value "sideways"`,
+		`{"minAge":"3 days"}`:                     "minAge:",
+		`{"constraint":"^1","range":{"min":"1"}}`: "mutually exclusive",
+		`{"limt":3}`:                              "unknown field",
+	}
+	for in, want := range cases {
+		opt := Options{Limit: 7}
+		err := json.Unmarshal([]byte(in), &opt)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want %q", in, err, want)
+		}
+		if opt.Limit != 7 {
+			t.Errorf("%s: options changed on error", in)
+		}
+	}
+}
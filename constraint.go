@@ -0,0 +1,309 @@
+package rats
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/woozymasta/semver"
+)
+
+// Constraint is a parsed npm/Composer-style version constraint, see ParseConstraint.
+type Constraint struct {
+	src  string
+	sets [][]comparator // OR of ANDs
+}
+
+// comparator is a single "OP VERSION" check.
+type comparator struct {
+	v  semver.Semver
+	op cmpOp
+}
+
+type cmpOp uint8
+
+const (
+	opEQ cmpOp = iota
+	opGT
+	opGTE
+	opLT
+	opLTE
+)
+
+// ParseConstraint parses a constraint expression:
+//
+//	1.2.3  =1.2.3       exact version
+//	1.2  1.2.x  1.*     any version of the series (>=1.2.0 <1.3.0-0)
+//	>1.2  >=1.2  <2  <=2.1.3
+//	~1.2.3              >=1.2.3 <1.3.0-0 (~1 is >=1.0.0 <2.0.0-0)
+//	^1.2.3              >=1.2.3 <2.0.0-0, left-most non-zero component is
+//	                    fixed: ^0.2.3 is >=0.2.3 <0.3.0-0, ^0.0.3 is <0.0.4-0
+//	1.2 - 2.3           hyphen range (>=1.2.0 <2.4.0-0)
+//	* or empty          any version
+//
+// Comparators separated by spaces or commas are ANDed, "||" separates OR'ed sets.
+// A leading 'v' is accepted. Like npm, a prerelease only matches when a
+// comparator of the same set names a prerelease of the same X.Y.Z.
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{src: s}
+
+	for _, part := range strings.Split(s, "||") {
+		set, err := parseConstraintSet(part)
+		if err != nil {
+			return nil, fmt.Errorf("constraint %q: %w", s, err)
+		}
+		c.sets = append(c.sets, set)
+	}
+
+	return c, nil
+}
+
+// String returns the source expression.
+func (c *Constraint) String() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.src
+}
+
+// Check reports whether v satisfies the constraint. Invalid versions never do.
+func (c *Constraint) Check(v semver.Semver) bool {
+	if c == nil || !v.Valid {
+		return false
+	}
+
+	for _, set := range c.sets {
+		if checkSet(set, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkSet ANDs the comparators of set and applies the prerelease rule.
+func checkSet(set []comparator, v semver.Semver) bool {
+	for _, cm := range set {
+		if !cm.match(v) {
+			return false
+		}
+	}
+
+	if !has(v.Flags, semver.FlagHasPre) {
+		return true
+	}
+
+	for _, cm := range set {
+		w := cm.v
+		if has(w.Flags, semver.FlagHasPre) &&
+			w.Major == v.Major && w.Minor == v.Minor && w.Patch == v.Patch {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cm comparator) match(v semver.Semver) bool {
+	c := v.Compare(cm.v)
+	switch cm.op {
+	case opGT:
+		return c > 0
+	case opGTE:
+		return c >= 0
+	case opLT:
+		return c < 0
+	case opLTE:
+		return c <= 0
+	default:
+		return c == 0
+	}
+}
+
+// parseConstraintSet parses space/comma separated comparators of one OR branch.
+func parseConstraintSet(s string) ([]comparator, error) {
+	toks := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	})
+
+	// glue operators written apart from their version: ">= 1.2"
+	for i := 0; i < len(toks)-1; i++ {
+		if strings.Trim(toks[i], "<>=~^") == "" && toks[i] != "" {
+			toks[i] += toks[i+1]
+			toks = append(toks[:i+1], toks[i+2:]...)
+		}
+	}
+
+	if len(toks) == 0 {
+		return []comparator{}, nil // any version
+	}
+
+	// hyphen range "A - B"
+	if len(toks) == 3 && toks[1] == "-" {
+		lo, loN, err := parsePartial(toks[0])
+		if err != nil {
+			return nil, err
+		}
+		hi, hiN, err := parsePartial(toks[2])
+		if err != nil {
+			return nil, err
+		}
+
+		set := expandComparator(">=", lo, loN)
+		return append(set, expandComparator("<=", hi, hiN)...), nil
+	}
+
+	set := make([]comparator, 0, 2*len(toks))
+	for _, t := range toks {
+		op := t[:len(t)-len(strings.TrimLeft(t, "<>=~^"))]
+		switch op {
+		case "", "=", "==", ">", ">=", "<", "<=", "~", "^":
+		default:
+			return nil, fmt.Errorf("unknown operator %q", op)
+		}
+
+		v, n, err := parsePartial(t[len(op):])
+		if err != nil {
+			return nil, err
+		}
+
+		set = append(set, expandComparator(op, v, n)...)
+	}
+
+	return set, nil
+}
+
+// parsePartial parses X, X.Y, X.Y.Z[-pre][+build] with optional 'x'/'X'/'*'
+// wildcards. Returns the version (missing parts zero) and the number of
+// given numeric components (0 for "*").
+func parsePartial(s string) (semver.Semver, int, error) {
+	if s == "" {
+		return semver.Semver{}, 0, errors.New("missing version")
+	}
+
+	parts := strings.Split(s, ".")
+	for i, p := range parts {
+		if p == "x" || p == "X" || p == "*" {
+			if i == 0 {
+				return semver.Semver{}, 0, nil
+			}
+			s = strings.Join(parts[:i], ".")
+			break
+		}
+	}
+
+	v, ok := semver.Parse(s)
+	if !ok || !v.Valid {
+		return semver.Semver{}, 0, fmt.Errorf("invalid version %q", s)
+	}
+
+	return v, componentCount(v.Flags), nil
+}
+
+// expandComparator desugars one "OP partial-version" into primitive comparators.
+func expandComparator(op string, v semver.Semver, n int) []comparator {
+	if n == 0 {
+		if op == "<" || op == ">" {
+			return []comparator{{op: opLT, v: mustVersion(0, 0, 0, "0")}} // nothing
+		}
+
+		return nil // any
+	}
+
+	// next returns the first version after the given partial series
+	next := func() semver.Semver {
+		if n == 1 {
+			return mustVersion(v.Major+1, 0, 0, "")
+		}
+
+		return mustVersion(v.Major, v.Minor+1, 0, "")
+	}
+
+	switch op {
+	case ">":
+		if n == 3 {
+			return []comparator{{op: opGT, v: v}}
+		}
+		return []comparator{{op: opGTE, v: next()}}
+
+	case ">=":
+		return []comparator{{op: opGTE, v: v}}
+
+	case "<":
+		if n == 3 {
+			return []comparator{{op: opLT, v: v}}
+		}
+		return []comparator{{op: opLT, v: mustVersion(v.Major, v.Minor, 0, "0")}}
+
+	case "<=":
+		if n == 3 {
+			return []comparator{{op: opLTE, v: v}}
+		}
+		return []comparator{{op: opLT, v: floorPre(next())}}
+
+	case "~":
+		return []comparator{{op: opGTE, v: v}, {op: opLT, v: floorPre(tildeNext(v, n))}}
+
+	case "^":
+		return []comparator{{op: opGTE, v: v}, {op: opLT, v: floorPre(caretNext(v, n))}}
+
+	default: // "", "=", "=="
+		if n == 3 {
+			return []comparator{{op: opEQ, v: v}}
+		}
+		return []comparator{{op: opGTE, v: v}, {op: opLT, v: floorPre(next())}}
+	}
+}
+
+// tildeNext is the exclusive upper bound of ~v: next minor, or next major for ~X.
+func tildeNext(v semver.Semver, n int) semver.Semver {
+	if n == 1 {
+		return mustVersion(v.Major+1, 0, 0, "")
+	}
+
+	return mustVersion(v.Major, v.Minor+1, 0, "")
+}
+
+// caretNext is the exclusive upper bound of ^v: bump the left-most non-zero
+// given component (or the last given one when all are zero).
+func caretNext(v semver.Semver, n int) semver.Semver {
+	switch {
+	case v.Major > 0 || n == 1:
+		return mustVersion(v.Major+1, 0, 0, "")
+	case v.Minor > 0 || n == 2:
+		return mustVersion(0, v.Minor+1, 0, "")
+	default:
+		return mustVersion(0, 0, v.Patch+1, "")
+	}
+}
+
+// floorPre returns v with the lowest prerelease "-0", so "< floorPre(v)"
+// also excludes prereleases of v.
+func floorPre(v semver.Semver) semver.Semver {
+	return mustVersion(v.Major, v.Minor, v.Patch, "0")
+}
+
+// mustVersion builds a valid semver value from numeric parts.
+func mustVersion(major, minor, patch int, pre string) semver.Semver {
+	s := fmt.Sprintf("%d.%d.%d", major, minor, patch)
+	if pre != "" {
+		s += "-" + pre
+	}
+
+	v, _ := semver.Parse(s)
+
+	return v
+}
+
+// applyConstraint keeps versions satisfying c.
+func applyConstraint(in []rec, c *Constraint) []rec {
+	out := in[:0]
+	for _, r := range in {
+		if c.Check(r.ver) {
+			out = append(out, r)
+		}
+	}
+
+	return out
+}
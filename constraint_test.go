@@ -0,0 +1,82 @@
+package rats
+
+import (
+	"testing"
+
+	"github.com/woozymasta/semver"
+)
+
+func TestParseConstraint_Check(t *testing.T) {
+	cases := []struct {
+		expr string
+		yes  []string
+		no   []string
+	}{
+		{"^1.2.3", []string{"1.2.3", "1.9.0", "v1.2.4"}, []string{"1.2.2", "2.0.0", "2.0.0-rc.1", "1.3.0-rc.1"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0", "0.2.2", "1.0.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4", "0.1.0"}},
+		{"^0.2", []string{"0.2.0", "0.2.7"}, []string{"0.3.0"}},
+		{"^0", []string{"0.0.1", "0.9.9"}, []string{"1.0.0"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0", "1.2.2"}},
+		{"~1", []string{"1.0.0", "1.9.9"}, []string{"2.0.0"}},
+		{">=1.2 <2", []string{"1.2.0", "1.99.0"}, []string{"1.1.9", "2.0.0", "2.0.0-rc.1"}},
+		{">= 1.2, < 2", []string{"1.5.0"}, []string{"2.0.0"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9", "1.0.0"}, []string{"1.3.0"}},
+		{"<1.2", []string{"1.1.9"}, []string{"1.2.0"}},
+		{"1.2.x", []string{"1.2.0", "1.2.5"}, []string{"1.3.0"}},
+		{"1.2", []string{"1.2.5"}, []string{"1.3.0"}},
+		{"=1.2.3", []string{"1.2.3", "v1.2.3+b"}, []string{"1.2.4"}},
+		{"1.2 - 2.3", []string{"1.2.0", "2.3.9"}, []string{"1.1.0", "2.4.0"}},
+		{"*", []string{"0.0.1", "9.0.0"}, []string{"1.0.0-rc.1"}},
+		{"", []string{"1.0.0"}, nil},
+		{">=1.2.0 <2.0.0 || >=3.0", []string{"1.2.0", "3.0.0", "4.1.0"}, []string{"2.0.0", "2.5.0", "1.1.0"}},
+		// prereleases only match on a comparator's X.Y.Z naming a prerelease
+		{">=1.2.3-rc.1 <2", []string{"1.2.3-rc.1", "1.2.3-rc.2", "1.2.3", "1.5.0"}, []string{"1.2.4-rc.1", "1.2.3-beta.1"}},
+		{"^1.2.3-beta", []string{"1.2.3-beta.2", "1.4.0"}, []string{"1.4.0-rc.1"}},
+	}
+
+	for _, c := range cases {
+		con, err := ParseConstraint(c.expr)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", c.expr, err)
+		}
+
+		for _, s := range c.yes {
+			if v, _ := semver.Parse(s); !con.Check(v) {
+				t.Fatalf("%q must match %q", c.expr, s)
+			}
+		}
+		for _, s := range c.no {
+			if v, _ := semver.Parse(s); con.Check(v) {
+				t.Fatalf("%q must not match %q", c.expr, s)
+			}
+		}
+	}
+}
+
+func TestParseConstraint_Errors(t *testing.T) {
+	for _, expr := range []string{"^", ">=foo", "!1.2", "1.2.3 || =>2", "1.2 - "} {
+		if _, err := ParseConstraint(expr); err == nil {
+			t.Fatalf("ParseConstraint(%q): want error", expr)
+		}
+	}
+}
+
+func TestSelect_Constraint(t *testing.T) {
+	in := []string{"0.2.3", "0.2.9", "0.3.0", "1.2.0", "1.5.0-rc.1", "2.0.0", "3.1.0", "latest"}
+
+	got := Select(in, Options{Constraint: "^0.2.3 || >=1.2 <2 || >=3"})
+	eqStrings(t, got, []string{"0.2.3", "0.2.9", "1.2.0", "3.1.0", "latest"}) // like Range, non-semver pass unless gated
+
+	if _, err := SelectErr(in, Options{Constraint: "^1.y"}); err == nil {
+		t.Fatalf("want parse error")
+	}
+	// like an invalid Range bound, an invalid Constraint is ignored by Select
+	eqStrings(t, Select(in, Options{Constraint: "^1.y"}), in)
+	eqStrings(t, Select(in, Options{Range: Range{Min: "junk"}}), in)
+
+	if _, err := SelectErr(in, Options{Constraint: "^1", Range: Range{Min: "1"}}); err == nil {
+		t.Fatalf("want mutual exclusion error")
+	}
+}
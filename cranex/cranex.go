@@ -0,0 +1,28 @@
+// Package cranex selects tags of a container registry repository, listing
+// them with crane from go-containerregistry. It lives in its own module so
+// the rats core stays network-agnostic and dependency-free.
+package cranex
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/woozymasta/rats"
+)
+
+// listTags lists the tags of a repository; crane.ListTags, swapped in tests.
+var listTags = crane.ListTags
+
+// SelectRepo lists the tags of the repository ref (e.g. "ghcr.io/org/app")
+// with crane.ListTags and runs rats.SelectErr on them. Registry auth,
+// transport and context go in craneOpts (crane.WithAuth,
+// crane.WithAuthFromKeychain, crane.WithContext, ...). Listing errors are
+// returned wrapped with ref.
+func SelectRepo(ref string, opt rats.Options, craneOpts ...crane.Option) ([]string, error) {
+	tags, err := listTags(ref, craneOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("list tags of %s: %w", ref, err)
+	}
+
+	return rats.SelectErr(tags, opt)
+}
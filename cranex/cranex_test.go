@@ -0,0 +1,54 @@
+package cranex
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/woozymasta/rats"
+)
+
+// fakeList replaces listTags for the test and records the options it got.
+func fakeList(t *testing.T, tags map[string][]string, nopts *int) {
+	t.Helper()
+
+	orig := listTags
+	t.Cleanup(func() { listTags = orig })
+
+	listTags = func(ref string, o ...crane.Option) ([]string, error) {
+		*nopts = len(o)
+		v, ok := tags[ref]
+		if !ok {
+			return nil, errors.New("repository not found")
+		}
+
+		return v, nil
+	}
+}
+
+func TestSelectRepo(t *testing.T) {
+	var nopts int
+	fakeList(t, map[string][]string{"ghcr.io/org/app": {"1.0.0", "1.1.0", "v1.1.1", "latest"}}, &nopts)
+
+	opt := rats.DefaultOptions()
+	got, err := SelectRepo("ghcr.io/org/app", opt, crane.WithUserAgent("rats"), crane.Insecure)
+	if err != nil {
+		t.Fatalf("SelectRepo: %v", err)
+	}
+	want, _ := rats.SelectErr([]string{"1.0.0", "1.1.0", "v1.1.1", "latest"}, opt)
+	if len(got) != len(want) {
+		t.Fatalf("got=%v, want=%v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got=%v, want=%v", got, want)
+		}
+	}
+	if nopts != 2 {
+		t.Fatalf("crane options passed: %d, want 2", nopts)
+	}
+
+	if _, err := SelectRepo("ghcr.io/org/missing", opt); err == nil {
+		t.Fatalf("want listing error")
+	}
+}
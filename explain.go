@@ -0,0 +1,177 @@
+package rats
+
+// TagDecision is the fate of one input tag in Explain.
+type TagDecision struct {
+	// Raw is the input tag.
+	Raw string
+
+	// Kept reports whether the tag is in the Select output.
+	Kept bool
+
+	// Reason names the first gate that dropped the tag, empty when kept:
+	//
+	//	vprefix     VPrefix
+	//	signature   ExcludeSignatures
+	//	include     Include, IncludeAny, IncludeSuffixes, IncludeGlob
+	//	exclude     Exclude, ExcludeAny, ExcludeSuffixes, ExcludePrefixes,
+	//	            ExcludeGlob, Ignore, DropDigestLike, MinAge
+	//	not-semver  FilterSemver or release gating of a non-semver tag
+	//	prerelease  release gating of a prerelease, PrereleaseOnly, PrereleaseChannels
+	//	format      Format, RequireFullVersion, ExactComponents
+	//	range       Range, Ranges, Constraint
+	//	dedup       Deduplicate
+	//	aggregated  Depth, DedupByMinor
+	//	limit       Offset, Limit
+	Reason string
+}
+
+// Explain runs the Select pipeline and reports, for every tag of in and in
+// its order, whether it was kept and otherwise the first gate that dropped
+// it. Meant for debugging a filter set that selects fewer tags than expected.
+// DedupOutput, applied to rendered strings, is not traced.
+func Explain(in []string, opt Options) []TagDecision {
+	opt = opt.normalized()
+
+	out := make([]TagDecision, len(in))
+	pass := make([]string, 0, len(in))
+	at := make([]int, 0, len(in)) // input index of pass[i]
+	for i, s := range in {
+		out[i].Raw = s
+		if why := rawGate(s, opt); why != "" {
+			out[i].Reason = why
+			continue
+		}
+
+		pass = append(pass, s)
+		at = append(at, i)
+	}
+
+	tr := &tracer{reason: make([]string, len(pass))}
+	rs := selectRecsWith(pass, opt, &workspace{trace: tr}, 0)
+	tr.resolve(rs, pass)
+
+	tr.mark(rs)
+	rs = limitRecs(rs, opt)
+	tr.drop(rs, "limit")
+
+	for i, why := range tr.reason {
+		out[at[i]].Reason = why
+	}
+	for _, r := range rs {
+		if r.idx >= 0 {
+			out[at[r.idx]].Kept = true
+		}
+	}
+
+	return out
+}
+
+// tracer records for Explain the first stage dropping each record, by the
+// record index (rec.idx). A nil tracer records nothing.
+type tracer struct {
+	reason []string
+	before []rec
+	kept   []bool
+}
+
+// mark remembers rs as the input of the next stage.
+func (t *tracer) mark(rs []rec) {
+	if t == nil {
+		return
+	}
+
+	t.before = append(t.before[:0], rs...)
+}
+
+// drop records reason for the marked records missing from rs.
+func (t *tracer) drop(rs []rec, reason string) {
+	if t == nil {
+		return
+	}
+
+	t.dropBy(rs, func(*rec) string { return reason })
+}
+
+// dropBy is drop with a reason per record.
+func (t *tracer) dropBy(rs []rec, reason func(r *rec) string) {
+	if t == nil {
+		return
+	}
+
+	if t.kept == nil {
+		t.kept = make([]bool, len(t.reason))
+	}
+	clear(t.kept)
+	for _, r := range rs {
+		if r.idx >= 0 {
+			t.kept[r.idx] = true
+		}
+	}
+
+	for i := range t.before {
+		r := &t.before[i]
+		if r.idx >= 0 && !t.kept[r.idx] && t.reason[r.idx] == "" {
+			t.reason[r.idx] = reason(r)
+		}
+	}
+}
+
+// dropCanonical is drop for the MatchCanonical regex gates.
+func (t *tracer) dropCanonical(rs []rec, opt Options) {
+	if t == nil {
+		return
+	}
+
+	t.dropBy(rs, func(r *rec) string { return regexGate(matchForm(canonicalForm(r), opt), opt) })
+}
+
+// dropNonSemver records "not-semver" for the non-semver records of rs
+// other than floating tags, dropped by SemVer gating.
+func (t *tracer) dropNonSemver(rs []rec, floating []string) {
+	if t == nil {
+		return
+	}
+
+	for i := range rs {
+		r := &rs[i]
+		if !r.ver.Valid && !isFloating(r.raw, floating) && t.reason[r.idx] == "" {
+			t.reason[r.idx] = "not-semver"
+		}
+	}
+}
+
+// resolve sets the index of the non-semver records of rs, which the
+// pipeline joins as plain strings, to an index of an equal tag that no stage
+// dropped and no other record of rs holds.
+func (t *tracer) resolve(rs []rec, pass []string) {
+	taken := make([]bool, len(pass))
+	for _, r := range rs {
+		if r.idx >= 0 {
+			taken[r.idx] = true
+		}
+	}
+
+	free := make(map[string][]int, 16)
+	for i, s := range pass {
+		if !taken[i] && t.reason[i] == "" {
+			free[s] = append(free[s], i)
+		}
+	}
+
+	for i := range rs {
+		r := &rs[i]
+		if q := free[r.raw]; r.idx < 0 && len(q) > 0 {
+			r.idx = q[0]
+			free[r.raw] = q[1:]
+		}
+	}
+}
+
+// gateReason is why release gating drops r.
+func gateReason(r *rec) string {
+	if r.ver.Prerelease != "" {
+		return "prerelease"
+	}
+
+	return "format"
+}
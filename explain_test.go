@@ -0,0 +1,103 @@
+package rats
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	in := []string{
+		"v1.0.0", "1.0.1", "latest", "v1.1.0-rc.1", "v1.1",
+		"v1.1.0", "v1.1.0", "v2.0.0-alpine", "v0.9.0", "v1.2.0", "v1.3.0",
+	}
+	opt := Options{
+		VPrefix:           PrefixV,
+		ExcludeSignatures: true,
+		Exclude:           regexp.MustCompile(`-alpine$`),
+		Format:            FormatXYZ,
+		Range:             Range{Min: "1.0.0"},
+		Deduplicate:       true,
+		Depth:             DepthMinor,
+		Sort:              SortDesc,
+		Limit:             2,
+	}
+
+	want := map[string]string{
+		"v1.0.0":        "",
+		"1.0.1":         "vprefix",
+		"latest":        "vprefix",
+		"v1.1.0-rc.1":   "prerelease",
+		"v1.1":          "format",
+		"v1.1.0":        "",
+		"v2.0.0-alpine": "exclude",
+		"v0.9.0":        "range",
+		"v1.2.0":        "",
+		"v1.3.0":        "",
+	}
+
+	got := Explain(in, opt)
+	if len(got) != len(in) {
+		t.Fatalf("got %d decisions, want %d", len(got), len(in))
+	}
+
+	var kept []string
+	for i, d := range got {
+		if d.Raw != in[i] {
+			t.Fatalf("#%d: raw %q, want %q", i, d.Raw, in[i])
+		}
+		if d.Kept {
+			kept = append(kept, d.Raw)
+			continue
+		}
+
+		w := want[d.Raw]
+		switch {
+		case i == 6:
+			w = "dedup" // second "v1.1.0"
+		case w == "":
+			w = "limit"
+		}
+		if d.Reason != w {
+			t.Errorf("%s (#%d): reason %q, want %q", d.Raw, i, d.Reason, w)
+		}
+	}
+	eqStrings(t, kept, []string{"v1.2.0", "v1.3.0"}) // input order
+	eqStrings(t, Select(in, opt), []string{"v1.3.0", "v1.2.0"})
+}
+
+func TestExplain_NonSemverAndAggregation(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"1.0.0", "1.0.1", "latest", "foo", "1.1.0"}
+
+	got := Explain(in, Options{Depth: DepthMinor, FilterSemver: true, FloatingTags: []string{"latest"}})
+	want := []TagDecision{
+		{Raw: "1.0.0", Reason: "aggregated"},
+		{Raw: "1.0.1", Kept: true},
+		{Raw: "latest", Kept: true},
+		{Raw: "foo", Reason: "not-semver"},
+		{Raw: "1.1.0", Kept: true},
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("#%d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	sig := "sha256-" + strings.Repeat("a", 64) + ".sig"
+	got = Explain([]string{sig, "1.0.0"}, Options{ExcludeSignatures: true})
+	if got[0].Reason != "signature" || !got[1].Kept {
+		t.Errorf("signature: got %+v", got)
+	}
+
+	// string-only input, cut by Limit
+	got = Explain([]string{"b", "a", "c"}, Options{Sort: SortAsc, Limit: 2})
+	for i, w := range []TagDecision{{Raw: "b", Kept: true}, {Raw: "a", Kept: true}, {Raw: "c", Reason: "limit"}} {
+		if got[i] != w {
+			t.Errorf("string-only #%d: got %+v, want %+v", i, got[i], w)
+		}
+	}
+}
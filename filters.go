@@ -1,7 +1,14 @@
 package rats
 
 import (
+	"cmp"
+	"regexp"
+	"runtime"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/woozymasta/semver"
 )
@@ -11,61 +18,512 @@ type rec struct {
 	raw string        // raw input string
 	ver semver.Semver // semver
 	idx int           // position
+
+	epoch, rev int32 // EpochAware "E:" epoch and "-R" revision, 0 when absent
 }
 
 // * raw prefilter (cheap, string-only)
 
-// preFilterRaw applies VPrefix / Include / Exclude / signature drop (when requested).
+// preFilterRaw applies VPrefix / suffix & prefix / Include / IncludeAny / IncludeGlob /
+// Exclude / ExcludeAny / ExcludeGlob / Ignore / signature / digest-like / age drop (when requested).
+// Globs are read from the compiled fields, so opt must be normalized.
 func preFilterRaw(in []string, opt Options) []string {
-	out := make([]string, 0, len(in))
+	return preFilterRawInto(make([]string, 0, len(in)), in, opt)
+}
+
+// preFilterRawInto is preFilterRaw appending the kept tags to out.
+func preFilterRawInto(out, in []string, opt Options) []string {
 	for _, s := range in {
-		// V prefix gate
-		if !acceptVPrefix(s, opt.VPrefix) {
-			continue
+		if rawGate(s, opt) == "" {
+			out = append(out, s)
 		}
+	}
 
-		// regex gates
-		if opt.Include != nil && !opt.Include.MatchString(s) {
-			continue
+	return out
+}
+
+// rawGate returns why the raw gates drop s (see TagDecision), or "" when s
+// passes them.
+func rawGate(s string, opt Options) string {
+	// V prefix gate
+	if !acceptVPrefix(s, opt.VPrefix) {
+		return "vprefix"
+	}
+
+	// plain affix gates (cheaper than regex)
+	if len(opt.IncludeSuffixes) > 0 && !hasAnySuffix(s, opt.IncludeSuffixes, opt.AffixIgnoreCase) {
+		return "include"
+	}
+
+	if hasAnySuffix(s, opt.ExcludeSuffixes, opt.AffixIgnoreCase) {
+		return "exclude"
+	}
+
+	if hasAnyPrefix(s, opt.ExcludePrefixes, opt.AffixIgnoreCase) {
+		return "exclude"
+	}
+
+	m := matchForm(s, opt)
+
+	// regex gates (after parsing with MatchCanonical)
+	if !opt.MatchCanonical {
+		if why := regexGate(m, opt); why != "" {
+			return why
 		}
+	}
+
+	// glob gates
+	if len(opt.IncludeGlob) > 0 && !matchAny(opt.includeGlob, m) {
+		return "include"
+	}
+
+	if matchAny(opt.excludeGlob, m) {
+		return "exclude"
+	}
+
+	if opt.Ignore != nil && opt.Ignore.Ignored(s) {
+		return "exclude"
+	}
+
+	// signatures drop (useful only when not strictly gating by semver, but cheap anyway)
+	if opt.ExcludeSignatures && isSigTagWith(m, opt.SignatureSuffixes) {
+		return "signature"
+	}
+
+	if opt.DropDigestLike && isDigestLike(s, opt.DigestLikeMinLen) {
+		return "exclude"
+	}
 
-		if opt.Exclude != nil && opt.Exclude.MatchString(s) {
+	// bake time
+	if opt.MinAge > 0 && !oldEnough(s, opt) {
+		return "exclude"
+	}
+
+	return ""
+}
+
+// matchForm is s as the regex, glob and signature gates see it: lowercased
+// with CaseInsensitive.
+func matchForm(s string, opt Options) string {
+	if opt.CaseInsensitive {
+		return strings.ToLower(s)
+	}
+
+	return s
+}
+
+// matchRegexps reports whether s passes Include/IncludeAny/Exclude/ExcludeAny.
+func matchRegexps(s string, opt Options) bool {
+	return regexGate(s, opt) == ""
+}
+
+// regexGate returns "include" or "exclude" for the regex gate s fails, or "".
+func regexGate(s string, opt Options) string {
+	if opt.Include != nil && !opt.Include.MatchString(s) {
+		return "include"
+	}
+
+	if len(opt.IncludeAny) > 0 && !matchAny(opt.IncludeAny, s) {
+		return "include"
+	}
+
+	if opt.Exclude != nil && opt.Exclude.MatchString(s) {
+		return "exclude"
+	}
+
+	if matchAny(opt.ExcludeAny, s) {
+		return "exclude"
+	}
+
+	return ""
+}
+
+// filterCanonical applies the regex gates of MatchCanonical in place: to the
+// canonical form of SemVer records and the raw tag of others. Returns the
+// kept records and how many of them are SemVer.
+func filterCanonical(rs []rec, opt Options) ([]rec, int) {
+	out := rs[:0]
+	semCount := 0
+	for _, r := range rs {
+		if !matchRegexps(matchForm(canonicalForm(&r), opt), opt) {
 			continue
 		}
 
-		// signatures drop (useful only when not strictly gating by semver, but cheap anyway)
-		if opt.ExcludeSignatures && isSigTag(s) {
-			continue
+		if r.ver.Valid {
+			semCount++
 		}
+		out = append(out, r)
+	}
 
-		out = append(out, s)
+	return out, semCount
+}
+
+// canonicalForm is the canonical form of a SemVer record, the raw tag otherwise.
+func canonicalForm(r *rec) string {
+	if r.ver.Valid {
+		return r.ver.Canonical()
 	}
 
-	return out
+	return r.raw
+}
+
+// matchAny reports whether any of res matches s.
+func matchAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re != nil && re.MatchString(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// oldEnough reports whether tag s was published at least MinAge before Now.
+func oldEnough(s string, opt Options) bool {
+	ts, ok := opt.Timestamps[s]
+	if !ok {
+		return !opt.DropUndated
+	}
+
+	return !ts.After(opt.Now.Add(-opt.MinAge))
 }
 
 // * parsing & classification
 
 // parseAll parses every tag. Returns all records and number of valid semver.
 func parseAll(in []string) ([]rec, int) {
-	rs := make([]rec, 0, len(in))
+	rs := make([]rec, len(in))
+
+	return rs, parseInto(rs, in, 0)
+}
+
+// parallelMinTags is the input size below which parsing stays serial.
+const parallelMinTags = 4096
+
+// parseAllInto is parseAll filling dst (same length as in), splitting the
+// input into contiguous chunks parsed by up to workers goroutines
+// (workers < 0: GOMAXPROCS); base is the input index of in[0].
+// Returns the number of valid semver.
+func parseAllInto(dst []rec, in []string, base, workers int) int {
+	if workers < 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers < 2 || len(in) < parallelMinTags {
+		return parseInto(dst, in, base)
+	}
+
+	chunk := (len(in) + workers - 1) / workers
+	counts := make([]int, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w*chunk < len(in); w++ {
+		lo := w * chunk
+		hi := min(lo+chunk, len(in))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counts[w] = parseInto(dst[lo:hi], in[lo:hi], base+lo)
+		}()
+	}
+	wg.Wait()
+
 	semCount := 0
+	for _, n := range counts {
+		semCount += n
+	}
+
+	return semCount
+}
 
-	for idx, s := range in {
-		r := rec{raw: s, idx: idx}
+// parseInto parses in into dst (same length); base is the input index of in[0].
+// Returns the number of valid semver.
+func parseInto(dst []rec, in []string, base int) int {
+	semCount := 0
+	for i, s := range in {
+		r := rec{raw: s, idx: base + i}
 		if v, ok := semver.Parse(s); ok && v.Valid {
 			r.ver = v
 			semCount++
 		}
 
-		rs = append(rs, r)
+		dst[i] = r
+	}
+
+	return semCount
+}
+
+// parseTags is parseAll plus the optional, option-driven parse fallbacks.
+func parseTags(in []string, opt Options) ([]rec, int) {
+	rs := make([]rec, len(in))
+
+	semCount := parseAllInto(rs, in, 0, opt.Parallelism)
+
+	return rs, semCount + parseFallbacks(rs, semCount, opt)
+}
+
+// parseFallbacks applies the option-driven parse fallbacks to the invalid
+// records of rs (semCount of them are valid). Returns the number of records
+// that became valid.
+func parseFallbacks(rs []rec, semCount int, opt Options) int {
+	n := 0
+	if len(opt.ReleaseQualifiers) > 0 && semCount+n < len(rs) {
+		n += parseQualified(rs, opt.ReleaseQualifiers)
+	}
+
+	if opt.NormalizePrereleaseNumbers && semCount+n < len(rs) {
+		n += parseZeroPaddedPre(rs)
+	}
+
+	// also reinterprets valid tags: "1.2.3-9" is revision 9, not a prerelease
+	if opt.EpochAware {
+		n += parseEpochRev(rs)
+	}
+
+	// last: also invalidates records that are not dates
+	if opt.CalVer {
+		n += parseCalVer(rs)
+	}
+
+	return n
+}
+
+// parseCalVer re-parses invalid records with zero-padded core components
+// ("2024.03.01") and invalidates every version that is not YYYY[.MM[.x]]
+// with MM in 1..12. Returns the net change in valid records.
+func parseCalVer(rs []rec) int {
+	n := 0
+	for i := range rs {
+		r := &rs[i]
+		if !r.ver.Valid {
+			s, changed := trimCoreZeros(r.raw)
+			if !changed {
+				continue
+			}
+
+			v, ok := semver.Parse(s)
+			if !ok || !v.Valid {
+				continue
+			}
+
+			v.Original = r.raw
+			r.ver = v
+			n++
+		}
+
+		if !isCalVer(r.ver) {
+			r.ver = semver.Semver{}
+			n--
+		}
+	}
+
+	return n
+}
+
+// isCalVer reports whether v has a 4-digit year major and, when present,
+// a 1..12 month minor.
+func isCalVer(v semver.Semver) bool {
+	if v.Major < 1000 || v.Major > 9999 {
+		return false
+	}
+
+	return !has(v.Flags, semver.FlagHasMinor) || (v.Minor >= 1 && v.Minor <= 12)
+}
+
+// trimCoreZeros strips leading zeros of the numeric components before the
+// first '-' or '+' ("v2024.03.01-rc.1" -> "v2024.3.1-rc.1"). Reports
+// whether anything changed.
+func trimCoreZeros(s string) (string, bool) {
+	end := len(s)
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		end = i
+	}
+
+	ids := strings.Split(s[:end], ".")
+	changed := false
+	for i, id := range ids {
+		digits := strings.TrimLeft(id, "vV")
+		if len(digits) < 2 || digits[0] != '0' || !isDigits(digits) {
+			continue
+		}
+
+		trimmed := strings.TrimLeft(digits, "0")
+		if trimmed == "" {
+			trimmed = "0"
+		}
+		ids[i] = id[:len(id)-len(digits)] + trimmed
+		changed = true
 	}
 
-	return rs, semCount
+	if !changed {
+		return s, false
+	}
+
+	return strings.Join(ids, ".") + s[end:], true
+}
+
+// parseEpochRev re-parses records of the form "[E:]CORE[-R]" (Debian-like,
+// E and R integers, at least one of them present) as CORE with epoch E and
+// revision R. Records whose CORE does not parse are left as they are.
+// Returns the number of records that became valid.
+func parseEpochRev(rs []rec) int {
+	n := 0
+	for i := range rs {
+		r := &rs[i]
+
+		core, epoch, rev, ok := splitEpochRev(r.raw)
+		if !ok {
+			continue
+		}
+
+		v, ok := semver.Parse(core)
+		if !ok || !v.Valid {
+			continue
+		}
+
+		if !r.ver.Valid {
+			n++
+		}
+
+		v.Original = r.raw
+		r.ver, r.epoch, r.rev = v, epoch, rev
+	}
+
+	return n
+}
+
+// splitEpochRev splits "[E:]CORE[-R]" into its parts; ok is false when s
+// has neither an epoch nor a revision.
+func splitEpochRev(s string) (core string, epoch, rev int32, ok bool) {
+	core = s
+	if i := strings.IndexByte(core, ':'); i >= 0 {
+		e, err := strconv.ParseInt(core[:i], 10, 32)
+		if !isDigits(core[:i]) || err != nil {
+			return s, 0, 0, false
+		}
+
+		core, epoch, ok = core[i+1:], int32(e), true
+	}
+
+	if i := strings.LastIndexByte(core, '-'); i > 0 && isDigits(core[i+1:]) {
+		if r, err := strconv.ParseInt(core[i+1:], 10, 32); err == nil {
+			core, rev, ok = core[:i], int32(r), true
+		}
+	}
+
+	return core, epoch, rev, ok
+}
+
+// parseQualified re-parses invalid records of the form "X.Y.Z.<qualifier>"
+// as release X.Y.Z. Returns the number of records that became valid.
+func parseQualified(rs []rec, quals []string) int {
+	n := 0
+	for i := range rs {
+		r := &rs[i]
+		if r.ver.Valid {
+			continue
+		}
+
+		dot := strings.LastIndexByte(r.raw, '.')
+		if dot < 0 || !hasQualifier(r.raw[dot+1:], quals) {
+			continue
+		}
+
+		v, ok := semver.Parse(r.raw[:dot])
+		if !ok || !v.Valid || !has(v.Flags, semver.FlagHasPatch) ||
+			has(v.Flags, semver.FlagHasPre) || has(v.Flags, semver.FlagHasBuild) {
+			continue
+		}
+
+		v.Original = r.raw
+		r.ver = v
+		n++
+	}
+
+	return n
+}
+
+// parseZeroPaddedPre re-parses invalid records after stripping leading zeros
+// from numeric prerelease identifiers. Returns the number of records that became valid.
+func parseZeroPaddedPre(rs []rec) int {
+	n := 0
+	for i := range rs {
+		r := &rs[i]
+		if r.ver.Valid {
+			continue
+		}
+
+		s, changed := trimPreZeros(r.raw)
+		if !changed {
+			continue
+		}
+
+		v, ok := semver.Parse(s)
+		if !ok || !v.Valid {
+			continue
+		}
+
+		v.Original = r.raw
+		r.ver = v
+		n++
+	}
+
+	return n
+}
+
+// trimPreZeros strips leading zeros of numeric identifiers in the prerelease
+// part (between the first '-' and '+'). Reports whether anything changed.
+func trimPreZeros(s string) (string, bool) {
+	dash := strings.IndexByte(s, '-')
+	if dash < 0 {
+		return s, false
+	}
+
+	end := len(s)
+	if plus := strings.IndexByte(s[dash:], '+'); plus >= 0 {
+		end = dash + plus
+	}
+
+	ids := strings.Split(s[dash+1:end], ".")
+	changed := false
+	for i, id := range ids {
+		if len(id) < 2 || id[0] != '0' || !isDigits(id) {
+			continue
+		}
+
+		id = strings.TrimLeft(id, "0")
+		if id == "" {
+			id = "0"
+		}
+		ids[i] = id
+		changed = true
+	}
+
+	if !changed {
+		return s, false
+	}
+
+	return s[:dash+1] + strings.Join(ids, ".") + s[end:], true
+}
+
+// hasQualifier reports whether q equals one of quals, case-insensitively.
+func hasQualifier(q string, quals []string) bool {
+	for _, x := range quals {
+		if strings.EqualFold(q, x) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // splitSemver separates valid semver recs and non-semver raw strings.
 func splitSemver(rs []rec) (sem []rec, other []string) {
+	return splitSemverInto(nil, nil, rs)
+}
+
+// splitSemverInto is splitSemver appending to sem and other.
+func splitSemverInto(sem []rec, other []string, rs []rec) ([]rec, []string) {
 	for _, r := range rs {
 		if r.ver.Valid {
 			sem = append(sem, r)
@@ -74,7 +532,31 @@ func splitSemver(rs []rec) (sem []rec, other []string) {
 		}
 	}
 
-	return
+	return sem, other
+}
+
+// keepFloating filters in in place to the tags equal to one of floating,
+// case-insensitively.
+func keepFloating(in []string, floating []string) []string {
+	out := in[:0]
+	for _, s := range in {
+		if isFloating(s, floating) {
+			out = append(out, s)
+		}
+	}
+
+	return out
+}
+
+// isFloating reports whether s equals one of floating, case-insensitively.
+func isFloating(s string, floating []string) bool {
+	for _, f := range floating {
+		if strings.EqualFold(s, f) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // * string-only pipeline
@@ -86,6 +568,10 @@ func stringOnlyPipeline(in []string, opt Options) []string {
 		sortStrings(in, true)
 	case SortDesc:
 		sortStrings(in, false)
+	case SortReverse:
+		slices.Reverse(in)
+	case SortNatural:
+		sortNatural(in)
 	default:
 		// as-is
 	}
@@ -108,14 +594,72 @@ func sortStrings(in []string, asc bool) {
 	})
 }
 
+// sortNatural sorts digit-aware: runs of digits compare numerically.
+func sortNatural(in []string) {
+	sort.SliceStable(in, func(i, j int) bool {
+		return naturalCompare(in[i], in[j]) < 0
+	})
+}
+
+// naturalCompare compares a and b chunk by chunk: digit runs numerically
+// (leading zeros ignored), other runs lexically; a digit run sorts before
+// text. Equal chunks fall back to plain comparison for a total order.
+func naturalCompare(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		ca, cb := chunkEnd(a, i), chunkEnd(b, j)
+		x, y := a[i:ca], b[j:cb]
+		i, j = ca, cb
+
+		xn, yn := isDigits(x), isDigits(y)
+		switch {
+		case xn && yn:
+			x, y = strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+			if len(x) != len(y) {
+				return cmp.Compare(len(x), len(y))
+			}
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		case xn:
+			return -1
+		case yn:
+			return 1
+		default:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+
+	if c := cmp.Compare(len(a)-i, len(b)-j); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a, b)
+}
+
+// chunkEnd returns the end of the digit or non-digit run starting at i.
+func chunkEnd(s string, i int) int {
+	digit := s[i] >= '0' && s[i] <= '9'
+	for i++; i < len(s); i++ {
+		if (s[i] >= '0' && s[i] <= '9') != digit {
+			break
+		}
+	}
+
+	return i
+}
+
 // * semver gating
 
-// filterReleaseOnly keeps only releases (no prerelease/build) and checks X/XY/XYZ form mask.
-func filterReleaseOnly(in []rec, fm Format) []rec {
+// filterReleaseOnly keeps only releases (no prerelease unless allowPre, no
+// build unless allowBuild) and checks X/XY/XYZ form mask.
+func filterReleaseOnly(in []rec, fm Format, allowBuild, allowPre bool) []rec {
 	out := in[:0]
 	for _, r := range in {
 		v := r.ver
-		if has(v.Flags, semver.FlagHasPre) || has(v.Flags, semver.FlagHasBuild) {
+		if (!allowPre && has(v.Flags, semver.FlagHasPre)) || (!allowBuild && has(v.Flags, semver.FlagHasBuild)) {
 			continue
 		}
 
@@ -131,6 +675,74 @@ func filterReleaseOnly(in []rec, fm Format) []rec {
 	return out
 }
 
+// filterPrerelease keeps only versions with a prerelease component.
+func filterPrerelease(in []rec) []rec {
+	out := in[:0]
+	for _, r := range in {
+		if has(r.ver.Flags, semver.FlagHasPre) {
+			out = append(out, r)
+		}
+	}
+
+	return out
+}
+
+// filterChannels keeps prereleases whose leading identifier is one of channels.
+func filterChannels(in []rec, channels []string) []rec {
+	out := in[:0]
+	for _, r := range in {
+		if !has(r.ver.Flags, semver.FlagHasPre) {
+			continue
+		}
+
+		ch, _, _ := strings.Cut(r.ver.Prerelease, ".")
+		for _, c := range channels {
+			if strings.EqualFold(ch, c) {
+				out = append(out, r)
+				break
+			}
+		}
+	}
+
+	return out
+}
+
+// filterFullVersion keeps only versions with an explicit patch component.
+func filterFullVersion(in []rec) []rec {
+	out := in[:0]
+	for _, r := range in {
+		if has(r.ver.Flags, semver.FlagHasPatch) {
+			out = append(out, r)
+		}
+	}
+
+	return out
+}
+
+// filterExactComponents keeps only versions with exactly n numeric components.
+func filterExactComponents(in []rec, n int) []rec {
+	out := in[:0]
+	for _, r := range in {
+		if componentCount(r.ver.Flags) == n {
+			out = append(out, r)
+		}
+	}
+
+	return out
+}
+
+// componentCount returns the number of explicit numeric components (1..3).
+func componentCount(f semver.Flags) int {
+	switch {
+	case has(f, semver.FlagHasPatch):
+		return 3
+	case has(f, semver.FlagHasMinor):
+		return 2
+	default:
+		return 1
+	}
+}
+
 func has(f semver.Flags, bit semver.Flags) bool {
 	return (f & bit) != 0
 }
@@ -151,33 +763,83 @@ func formFromFlags(f semver.Flags) Format {
 // * range
 
 func applyRange(in []rec, r Range) []rec {
+	return applyRanges(in, []Range{r})
+}
+
+// applyRanges keeps versions inside at least one enabled range.
+func applyRanges(in []rec, rs []Range) []rec {
 	if len(in) == 0 {
 		return in
 	}
-	minV, hasMin := parseBound(r.Min, r.IncludePrerelease, false)
-	maxV, hasMax := parseBound(r.Max, r.IncludePrerelease, true)
+
+	bs := make([]rangeBounds, 0, len(rs))
+	for _, r := range rs {
+		if r.Enabled() {
+			bs = append(bs, compileRange(r))
+		}
+	}
 
 	out := in[:0]
 	for _, it := range in {
-		v := it.ver
-		if hasMin {
-			c := v.Compare(minV)
-			if c < 0 || (c == 0 && r.MinExclusive) {
-				continue
+		for _, b := range bs {
+			if b.contains(it.ver) {
+				out = append(out, it)
+				break
 			}
 		}
+	}
 
-		if hasMax {
-			c := v.Compare(maxV)
-			if c > 0 || (c == 0 && r.MaxExclusive) {
-				continue
-			}
+	return out
+}
+
+// rangeBounds is a Range with parsed bounds.
+type rangeBounds struct {
+	minV, maxV     semver.Semver
+	hasMin, hasMax bool
+	minEx, maxEx   bool
+	maxDropPre     bool // drop prereleases of the X.Y.Z of maxV
+}
+
+// compileRange parses the bounds of r. For a release ceiling, e.g. Max "1.2.3":
+//
+//	MaxExclusive  ExcludePrereleaseAtMax  keeps 1.2.3  keeps 1.2.3-rc.1
+//	false         false                   yes          yes
+//	false         true                    yes          no
+//	true          false                   no           yes
+//	true          true                    no           no
+//
+// A prerelease ceiling ("1.2.3-rc.2") always uses plain SemVer precedence.
+func compileRange(r Range) rangeBounds {
+	b := rangeBounds{minEx: r.MinExclusive, maxEx: r.MaxExclusive}
+	b.minV, b.hasMin = parseBound(r.Min, r.IncludePrerelease, false)
+	b.maxV, b.hasMax = parseBound(r.Max, r.IncludePrerelease, true)
+	b.maxDropPre = b.hasMax && r.ExcludePrereleaseAtMax && !has(b.maxV.Flags, semver.FlagHasPre)
+
+	return b
+}
+
+// contains reports whether v is within the bounds.
+func (b rangeBounds) contains(v semver.Semver) bool {
+	if b.hasMin {
+		c := v.Compare(b.minV)
+		if c < 0 || (c == 0 && b.minEx) {
+			return false
+		}
+	}
+
+	if b.hasMax {
+		c := v.Compare(b.maxV)
+		if c > 0 || (c == 0 && b.maxEx) {
+			return false
 		}
 
-		out = append(out, it)
+		if b.maxDropPre && has(v.Flags, semver.FlagHasPre) &&
+			v.Major == b.maxV.Major && v.Minor == b.maxV.Minor && v.Patch == b.maxV.Patch {
+			return false
+		}
 	}
 
-	return out
+	return true
 }
 
 func parseBound(s string, includePre bool, isMax bool) (semver.Semver, bool) {
@@ -208,86 +870,261 @@ func parseBound(s string, includePre bool, isMax bool) (semver.Semver, bool) {
 type dkey struct {
 	pre           string
 	maj, min, pat int
+	epoch, rev    int32
+}
+
+// keyOf returns the dedup identity of v: MAJOR.MINOR.PATCH + PRERELEASE.
+func keyOf(v semver.Semver) dkey {
+	return dkey{maj: v.Major, min: v.Minor, pat: v.Patch, pre: v.Prerelease}
+}
+
+// recKey is keyOf plus the EpochAware epoch and revision of r.
+func recKey(r *rec) dkey {
+	k := keyOf(r.ver)
+	k.epoch, k.rev = r.epoch, r.rev
+
+	return k
 }
 
-func deduplicate(in []rec) []rec {
-	seen := make(map[dkey]struct{}, len(in))
+func deduplicate(in []rec, prefer DedupPrefer) []rec {
+	seen := make(map[dkey]int, len(in)) // key -> position in out
 	out := in[:0]
 
 	for _, r := range in {
-		v := r.ver
-		k := dkey{maj: v.Major, min: v.Minor, pat: v.Patch, pre: v.Prerelease}
-		if _, ok := seen[k]; ok {
+		k := recKey(&r)
+		if i, ok := seen[k]; ok {
+			if preferAlias(r, out[i], prefer) {
+				out[i] = r
+			}
 			continue
 		}
 
-		seen[k] = struct{}{}
+		seen[k] = len(out)
 		out = append(out, r)
 	}
 
 	return out
 }
 
+// preferAlias reports whether alias r should replace the current survivor b
+// (which was seen earlier, so ties keep b).
+func preferAlias(r, b rec, prefer DedupPrefer) bool {
+	switch prefer {
+	case PreferCanonical:
+		return canonicalRank(&r) > canonicalRank(&b)
+	case PreferHighestBuild:
+		return compareBuild(r.ver.Build, b.ver.Build) > 0
+	case PreferShortest:
+		return len(r.raw) < len(b.raw)
+	default:
+		return false
+	}
+}
+
+// canonicalRank scores how close the raw tag is to the canonical form.
+func canonicalRank(r *rec) int {
+	full := has(r.ver.Flags, semver.FlagHasMinor) && has(r.ver.Flags, semver.FlagHasPatch)
+	noBuild := !has(r.ver.Flags, semver.FlagHasBuild)
+
+	switch {
+	case r.raw == r.ver.Canonical():
+		return 3
+	case full && noBuild:
+		return 2
+	case full:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareBuild orders build metadata like prerelease identifiers; empty is lowest.
+func compareBuild(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return -1
+	case b == "":
+		return 1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, y := as[i], bs[i]
+		xn, yn := isDigits(x), isDigits(y)
+
+		switch {
+		case xn && yn:
+			x, y = strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+			if len(x) != len(y) {
+				return cmp.Compare(len(x), len(y))
+			}
+			if x != y {
+				return strings.Compare(x, y)
+			}
+		case xn:
+			return -1
+		case yn:
+			return 1
+		case x != y:
+			return strings.Compare(x, y)
+		}
+	}
+
+	return cmp.Compare(len(as), len(bs))
+}
+
 // * aggregation (Depth)
 
 func aggregateMinor(in []rec) []rec {
-	type best struct{ r rec }
-	by := make(map[uint64]best, len(in))
-	order := make([]uint64, 0, 64)
+	return aggregateBest(make([]rec, 0, 64), in, minorKey, make(map[uint64]int, 64))
+}
+
+// aggregateBest keeps the greatest version per key (lowest input index on
+// ties), groups in first-seen order, appending them to out. pos must be empty.
+// out may share in's backing array from the start (out = in[:0]): a group is
+// only ever written at or before the record being read.
+func aggregateBest(out, in []rec, key func(semver.Semver) uint64, pos map[uint64]int) []rec {
+	for _, r := range in {
+		k := key(r.ver)
+
+		i, ok := pos[k]
+		if !ok {
+			pos[k] = len(out)
+			out = append(out, r)
+			continue
+		}
 
-	pack := func(maj, minV int) uint64 {
-		if maj < 0 || minV < 0 {
-			return 0 // semver never gives negative, just a guard
+		c := compareRecs(&r, &out[i])
+		if c > 0 || (c == 0 && r.idx < out[i].idx) {
+			out[i] = r
 		}
-		// #nosec G115 -- semver major/minor are bounded, safe to cast
-		return (uint64(maj) << 32) | uint64(minV&0xffffffff)
 	}
 
+	return out
+}
+
+// minorKey packs (major, minor) into a single map key.
+func minorKey(v semver.Semver) uint64 {
+	if v.Major < 0 || v.Minor < 0 {
+		return 0 // semver never gives negative, just a guard
+	}
+	// #nosec G115 -- semver major/minor are bounded, safe to cast
+	return (uint64(v.Major) << 32) | uint64(v.Minor&0xffffffff)
+}
+
+// majorKey is the map key of the major series.
+func majorKey(v semver.Semver) uint64 {
+	if v.Major < 0 {
+		return 0
+	}
+	// #nosec G115 -- semver major is non-negative here
+	return uint64(v.Major)
+}
+
+// latestKey puts every version into a single group.
+func latestKey(semver.Semver) uint64 { return 0 }
+
+// dropShadowedPre removes prereleases from every key bucket that also has a
+// release, so aggregation prefers releases but keeps prerelease-only buckets.
+func dropShadowedPre(in []rec, key func(semver.Semver) uint64) []rec {
+	hasRelease := make(map[uint64]bool, len(in))
 	for _, r := range in {
-		v := r.ver
-		k := pack(v.Major, v.Minor)
+		if !has(r.ver.Flags, semver.FlagHasPre) {
+			hasRelease[key(r.ver)] = true
+		}
+	}
 
-		if b, ok := by[k]; ok {
-			c := v.Compare(b.r.ver)
-			if c > 0 || (c == 0 && r.idx < b.r.idx) {
-				by[k] = best{r: r}
-			}
-		} else {
-			by[k] = best{r: r}
+	out := in[:0]
+	for _, r := range in {
+		if has(r.ver.Flags, semver.FlagHasPre) && hasRelease[key(r.ver)] {
+			continue
+		}
+		out = append(out, r)
+	}
+
+	return out
+}
+
+// aggregateTopN keeps the n best records per key. Groups keep first-seen
+// order, members are best first (equal versions by input order).
+func aggregateTopN(in []rec, n int, key func(semver.Semver) uint64) []rec {
+	by := make(map[uint64][]rec, len(in))
+	order := make([]uint64, 0, 64)
+
+	for _, r := range in {
+		k := key(r.ver)
+		if _, ok := by[k]; !ok {
 			order = append(order, k)
 		}
+		by[k] = append(by[k], r)
 	}
 
-	out := make([]rec, 0, len(by))
+	out := make([]rec, 0, len(in))
 	for _, k := range order {
-		out = append(out, by[k].r)
+		g := by[k]
+		sort.SliceStable(g, func(i, j int) bool {
+			c := compareRecs(&g[i], &g[j])
+			if c != 0 {
+				return c > 0
+			}
+
+			return g[i].idx < g[j].idx
+		})
+		out = append(out, g[:min(n, len(g))]...)
 	}
 
 	return out
 }
 
 func aggregateMajor(in []rec) []rec {
-	type best struct{ r rec }
-	by := make(map[int]best, len(in))
+	return aggregateBest(make([]rec, 0, 16), in, majorKey, make(map[uint64]int, 16))
+}
+
+// aggregateMajorChannels keeps per major the best release and the best
+// prerelease when it is newer than that release. Majors in first-seen order,
+// release before prerelease.
+func aggregateMajorChannels(in []rec) []rec {
+	type best struct {
+		stable, pre       rec
+		hasStable, hasPre bool
+	}
+	by := make(map[int]*best, len(in))
 	order := make([]int, 0, 64)
 
+	better := func(r, b rec) bool {
+		c := compareRecs(&r, &b)
+		return c > 0 || (c == 0 && r.idx < b.idx)
+	}
+
 	for _, r := range in {
-		v := r.ver
-		k := v.Major
-		if b, ok := by[k]; ok {
-			c := v.Compare(b.r.ver)
-			if c > 0 || (c == 0 && r.idx < b.r.idx) {
-				by[k] = best{r: r}
-			}
-		} else {
-			by[k] = best{r: r}
+		k := r.ver.Major
+		b, ok := by[k]
+		if !ok {
+			b = &best{}
+			by[k] = b
 			order = append(order, k)
 		}
+
+		if has(r.ver.Flags, semver.FlagHasPre) {
+			if !b.hasPre || better(r, b.pre) {
+				b.pre, b.hasPre = r, true
+			}
+		} else if !b.hasStable || better(r, b.stable) {
+			b.stable, b.hasStable = r, true
+		}
 	}
 
-	out := make([]rec, 0, len(by))
+	out := make([]rec, 0, 2*len(by))
 	for _, k := range order {
-		out = append(out, by[k].r)
+		b := by[k]
+		if b.hasStable {
+			out = append(out, b.stable)
+		}
+		if b.hasPre && (!b.hasStable || compareRecs(&b.pre, &b.stable) > 0) {
+			out = append(out, b.pre)
+		}
 	}
 
 	return out
@@ -300,8 +1137,7 @@ func aggregateLatest(in []rec) []rec {
 
 	best := in[0]
 	for i := 1; i < len(in); i++ {
-		v := in[i].ver
-		c := v.Compare(best.ver)
+		c := compareRecs(&in[i], &best)
 		if c > 0 || (c == 0 && in[i].idx < best.idx) {
 			best = in[i]
 		}
@@ -310,33 +1146,166 @@ func aggregateLatest(in []rec) []rec {
 	return []rec{best}
 }
 
+// * limit
+
+// spreadMajors picks limit records: first one per major, then the rest in order.
+// The relative order of picked records is preserved.
+func spreadMajors(in []rec, limit int) []rec {
+	if limit <= 0 || limit >= len(in) {
+		return in
+	}
+
+	keep := make([]bool, len(in))
+	seen := make(map[int]struct{}, 16)
+	n := 0
+
+	for i, r := range in {
+		if n == limit {
+			break
+		}
+		if !r.ver.Valid {
+			continue
+		}
+		if _, ok := seen[r.ver.Major]; ok {
+			continue
+		}
+
+		seen[r.ver.Major] = struct{}{}
+		keep[i] = true
+		n++
+	}
+
+	for i := range in {
+		if n == limit {
+			break
+		}
+		if !keep[i] {
+			keep[i] = true
+			n++
+		}
+	}
+
+	out := make([]rec, 0, limit)
+	for i, r := range in {
+		if keep[i] {
+			out = append(out, r)
+		}
+	}
+
+	return out
+}
+
 // * Sorting
 
-func sortSemver(in []rec, asc bool) {
+// sortSemver orders records by version, ties broken per tb. The comparison
+// is total (input index last), so an unstable sort gives a stable result
+// with far fewer moves of the large records than a stable merge.
+func sortSemver(in []rec, asc bool, tb TieBreak) {
 	if len(in) < 2 {
 		return
 	}
 
-	sort.SliceStable(in, func(i, j int) bool {
-		a, b := in[i], in[j]
-		c := a.ver.Compare(b.ver)
-		if c == 0 {
-			// deterministic tie-breaker: lex raw, then by input order
-			if a.raw != b.raw {
-				if asc {
-					return a.raw < b.raw
-				}
-				return a.raw > b.raw
+	sort.Slice(in, func(i, j int) bool {
+		return semverLess(&in[i], &in[j], asc, tb) // no copies of the parsed versions
+	})
+}
+
+// topSemver returns the first k records of sortSemver(in) without sorting
+// all of in: a bounded heap of the k best is kept in in[:k], then sorted.
+// The order is total, so the result equals a full sort truncated to k.
+// k <= 0 or k >= len(in) sorts everything.
+func topSemver(in []rec, k int, asc bool, tb TieBreak) []rec {
+	if k <= 0 || k >= len(in) {
+		sortSemver(in, asc, tb)
+		return in
+	}
+
+	// max-heap by output order: in[0] is the record that would come last
+	h := in[:k]
+	down := func(i int) {
+		for {
+			j := 2*i + 1
+			if j >= k {
+				return
+			}
+			if r := j + 1; r < k && semverLess(&h[j], &h[r], asc, tb) {
+				j = r
+			}
+			if !semverLess(&h[i], &h[j], asc, tb) {
+				return
 			}
-			return a.idx < b.idx
+			h[i], h[j] = h[j], h[i]
+			i = j
+		}
+	}
+
+	for i := k/2 - 1; i >= 0; i-- {
+		down(i)
+	}
+
+	for j := k; j < len(in); j++ {
+		if semverLess(&in[j], &h[0], asc, tb) {
+			h[0] = in[j]
+			down(0)
+		}
+	}
+
+	sortSemver(h, asc, tb)
+
+	return h
+}
+
+// semverLess reports whether a sorts before b.
+func semverLess(a, b *rec, asc bool, tb TieBreak) bool {
+	c := compareRecs(a, b)
+	if c == 0 && tb&tieBuild != 0 {
+		c = compareBuild(a.ver.Build, b.ver.Build)
+	}
+	if c == 0 {
+		return tieLess(a, b, asc, tb&^tieBuild)
+	}
+
+	if asc {
+		return c < 0
+	}
+
+	return c > 0
+}
+
+// compareRecs compares versions by (epoch, SemVer, revision); epoch and
+// revision are 0 unless EpochAware found them.
+func compareRecs(a, b *rec) int {
+	if a.epoch != b.epoch {
+		return cmp.Compare(a.epoch, b.epoch)
+	}
+
+	if c := a.ver.Compare(b.ver); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(a.rev, b.rev)
+}
+
+// tieLess orders records with equal versions (deterministic tie-breaker).
+func tieLess(a, b *rec, asc bool, tb TieBreak) bool {
+	switch tb {
+	case TieInputOrder:
+		return a.idx < b.idx
+	case TieShortest:
+		if len(a.raw) != len(b.raw) {
+			return len(a.raw) < len(b.raw)
 		}
+	}
 
+	// lex raw (follows direction), then by input order
+	if a.raw != b.raw {
 		if asc {
-			return c < 0
+			return a.raw < b.raw
 		}
+		return a.raw > b.raw
+	}
 
-		return c > 0
-	})
+	return a.idx < b.idx
 }
 
 // * V prefix
@@ -357,3 +1326,52 @@ func acceptVPrefix(s string, mode VPrefix) bool {
 		return true
 	}
 }
+
+// setVPrefix adds (PrefixV) or strips (PrefixNone) a leading 'v'/'V'.
+func setVPrefix(s string, mode VPrefix) string {
+	hasV := len(s) > 0 && (s[0] == 'v' || s[0] == 'V')
+	switch {
+	case mode == PrefixV && !hasV:
+		return "v" + s
+	case mode == PrefixNone && hasV:
+		return s[1:]
+	default:
+		return s
+	}
+}
+
+// * diagnostics
+
+// shorthandConflicts finds shorthand records sharing a series with full versions.
+func shorthandConflicts(rs []rec) []ShorthandWarning {
+	byMajor := make(map[int][]string, 16)
+	byMinor := make(map[[2]int][]string, 16)
+	for _, r := range rs {
+		if r.ver.Valid && has(r.ver.Flags, semver.FlagHasPatch) {
+			byMajor[r.ver.Major] = append(byMajor[r.ver.Major], r.raw)
+			k := [2]int{r.ver.Major, r.ver.Minor}
+			byMinor[k] = append(byMinor[k], r.raw)
+		}
+	}
+
+	var out []ShorthandWarning
+	for _, r := range rs {
+		if !r.ver.Valid {
+			continue
+		}
+
+		var full []string
+		switch componentCount(r.ver.Flags) {
+		case 1:
+			full = byMajor[r.ver.Major]
+		case 2:
+			full = byMinor[[2]int{r.ver.Major, r.ver.Minor}]
+		}
+
+		if len(full) > 0 {
+			out = append(out, ShorthandWarning{Shorthand: r.raw, Full: full})
+		}
+	}
+
+	return out
+}
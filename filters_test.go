@@ -2,9 +2,12 @@
 package rats
 
 import (
+	"fmt"
 	"regexp"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 // * helpers
@@ -73,6 +76,19 @@ func TestPreFilterRaw(t *testing.T) {
 	eqStrings(t, got, want)
 }
 
+func TestPreFilterRaw_AnyRegex(t *testing.T) {
+	in := []string{"1.0.0", "1.0.0-alpine", "1.0.0-slim", "nightly-20250101", "2.0.0-rc.1", "x"}
+	opt := Options{
+		IncludeAny: []*regexp.Regexp{regexp.MustCompile(`^\d`), regexp.MustCompile(`^nightly`)},
+		ExcludeAny: []*regexp.Regexp{regexp.MustCompile(`-alpine$`), nil, regexp.MustCompile(`-slim$`)},
+	}
+	eqStrings(t, preFilterRaw(in, opt), []string{"1.0.0", "nightly-20250101", "2.0.0-rc.1"})
+
+	// ANDed with Include
+	opt.Include = regexp.MustCompile(`^1\.`)
+	eqStrings(t, preFilterRaw(in, opt), []string{"1.0.0"})
+}
+
 // * parseAll / splitSemver
 
 func TestParseAllAndSplit(t *testing.T) {
@@ -90,6 +106,29 @@ func TestParseAllAndSplit(t *testing.T) {
 	eqStrings(t, other, []string{"1.2.3.4", "foo"})
 }
 
+func TestParseAllParallel_MatchesSerial(t *testing.T) {
+	in := makeTags(3*parallelMinTags + 17)
+
+	want, wantN := parseAll(in)
+	for _, workers := range []int{-1, 2, 3, 7, 64} {
+		got := make([]rec, len(in))
+		n := parseAllInto(got, in, 0, workers)
+		if n != wantN || len(got) != len(want) {
+			t.Fatalf("workers=%d: n=%d len=%d, want %d/%d", workers, n, len(got), wantN, len(want))
+		}
+		for i := range got {
+			if got[i].raw != want[i].raw || got[i].idx != want[i].idx || got[i].ver.Compare(want[i].ver) != 0 {
+				t.Fatalf("workers=%d: rec %d = %+v, want %+v", workers, i, got[i], want[i])
+			}
+		}
+	}
+
+	opt := Options{FilterSemver: true, Deduplicate: true, Sort: SortDesc, Depth: DepthMinor}
+	serial := Select(in, opt)
+	opt.Parallelism = 4
+	eqStrings(t, Select(in, opt), serial)
+}
+
 // * stringOnlyPipeline
 
 func TestStringOnlyPipeline_Sort(t *testing.T) {
@@ -111,7 +150,7 @@ func TestFilterReleaseOnly_FormatMask(t *testing.T) {
 	rs := parseRecs(t, tags)
 
 	// Allow X, XY, XYZ
-	keepAll := filterReleaseOnly(append([]rec{}, rs...), FormatAll)
+	keepAll := filterReleaseOnly(append([]rec{}, rs...), FormatAll, false, false)
 	got := make([]string, 0, len(keepAll))
 	for _, r := range keepAll {
 		got = append(got, r.raw)
@@ -119,7 +158,7 @@ func TestFilterReleaseOnly_FormatMask(t *testing.T) {
 	eqStrings(t, got, []string{"1", "1.2", "1.2.3", "v2"})
 
 	// Only XYZ
-	onlyXYZ := filterReleaseOnly(append([]rec{}, rs...), FormatXYZ)
+	onlyXYZ := filterReleaseOnly(append([]rec{}, rs...), FormatXYZ, false, false)
 	got = got[:0]
 	for _, r := range onlyXYZ {
 		got = append(got, r.raw)
@@ -159,7 +198,7 @@ func TestDeduplicate_CorePlusPrerelease(t *testing.T) {
 	tags := []string{"1.2.3", "v1.2.3", "1.2.3+build5", "1.2.3-rc.1", "1.2.3-rc.1+xyz"}
 	sem := parseRecs(t, tags)
 
-	got := deduplicate(append([]rec{}, sem...))
+	got := deduplicate(append([]rec{}, sem...), PreferFirstSeen)
 	// Expect first release "1.2.3" and first prerelease "1.2.3-rc.1" kept
 	out := make([]string, 0, len(got))
 	for _, r := range got {
@@ -222,7 +261,7 @@ func TestSortSemver(t *testing.T) {
 	sem := parseRecs(t, tags)
 
 	cp := append([]rec{}, sem...)
-	sortSemver(cp, true)
+	sortSemver(cp, true, TieLexical)
 	out := make([]string, 0, len(cp))
 	for _, r := range cp {
 		out = append(out, r.raw)
@@ -230,7 +269,7 @@ func TestSortSemver(t *testing.T) {
 	eqStrings(t, out, []string{"1.0.0-rc.1", "1.0.0", "1.10.0", "2.0.0"})
 
 	cp = append([]rec{}, sem...)
-	sortSemver(cp, false)
+	sortSemver(cp, false, TieLexical)
 	out = out[:0]
 	for _, r := range cp {
 		out = append(out, r.raw)
@@ -282,3 +321,675 @@ func TestNoAnySemver(t *testing.T) {
 	want := []string{"one", "two", "some"}
 	eqStrings(t, got, want)
 }
+
+func TestSelect_DropDigestLike(t *testing.T) {
+	in := []string{"1.2.3", "abc1234", "a1b2c3", "1234567", "latest", "0123abcdef"}
+
+	got := Select(in, Options{DropDigestLike: true})
+	// semver first, then non-semver in input order
+	eqStrings(t, got, []string{"1.2.3", "1234567", "a1b2c3", "latest"})
+
+	got = Select(in, Options{DropDigestLike: true, DigestLikeMinLen: 6})
+	eqStrings(t, got, []string{"1.2.3", "1234567", "latest"})
+}
+
+func TestSelect_DedupByMinor(t *testing.T) {
+	in := []string{"1.3.0", "1.2.1", "2.0.0", "1.2.5", "1.3.2", "1.2.0", "foo"}
+
+	got := Select(in, Options{DedupByMinor: true, FilterSemver: true})
+	// groups in first-seen order (1.3, 1.2, 2.0), each as its latest patch
+	eqStrings(t, got, []string{"1.3.2", "1.2.5", "2.0.0"})
+
+	got = Select(in, Options{DedupByMinor: true, FilterSemver: true, Sort: SortAsc})
+	eqStrings(t, got, []string{"1.2.5", "1.3.2", "2.0.0"})
+}
+
+func TestSelect_LimitSpreadMajors(t *testing.T) {
+	in := []string{
+		"3.2.0", "3.1.0", "3.0.1", "3.0.0",
+		"2.1.0", "2.0.0",
+		"1.9.0", "1.8.0",
+	}
+	opt := Options{FilterSemver: true, Sort: SortDesc, Limit: 4}
+
+	// plain limit: everything from major 3
+	eqStrings(t, Select(in, opt), []string{"3.2.0", "3.1.0", "3.0.1", "3.0.0"})
+
+	opt.LimitSpreadMajors = true
+	eqStrings(t, Select(in, opt), []string{"3.2.0", "3.1.0", "2.1.0", "1.9.0"})
+
+	// fewer slots than majors: top majors win
+	opt.Limit = 2
+	eqStrings(t, Select(in, opt), []string{"3.2.0", "2.1.0"})
+}
+
+func TestSetVPrefix(t *testing.T) {
+	cases := []struct {
+		s    string
+		mode VPrefix
+		want string
+	}{
+		{"1.2.3", PrefixV, "v1.2.3"},
+		{"v1.2.3", PrefixV, "v1.2.3"},
+		{"V1.2.3", PrefixV, "V1.2.3"},
+		{"v1.2.3", PrefixNone, "1.2.3"},
+		{"V1", PrefixNone, "1"},
+		{"1.2.3", PrefixNone, "1.2.3"},
+		{"v1.2.3", PrefixAny, "v1.2.3"},
+	}
+	for _, c := range cases {
+		if got := setVPrefix(c.s, c.mode); got != c.want {
+			t.Fatalf("setVPrefix(%q,%v)=%q, want %q", c.s, c.mode, got, c.want)
+		}
+	}
+}
+
+func TestSelect_NormalizeAggregatedPrefix(t *testing.T) {
+	in := []string{"v2.1.0", "2.0.3", "v2.0.1", "1.5.0", "v1.4.0"}
+	opt := Options{FilterSemver: true, Depth: DepthMinor, Sort: SortDesc}
+
+	// mixed raw forms by default
+	eqStrings(t, Select(in, opt), []string{"v2.1.0", "2.0.3", "1.5.0", "v1.4.0"})
+
+	opt.NormalizeAggregatedPrefix = PrefixV
+	eqStrings(t, Select(in, opt), []string{"v2.1.0", "v2.0.3", "v1.5.0", "v1.4.0"})
+
+	opt.NormalizeAggregatedPrefix = PrefixNone
+	eqStrings(t, Select(in, opt), []string{"2.1.0", "2.0.3", "1.5.0", "1.4.0"})
+
+	// no aggregation -> untouched
+	opt.Depth = DepthPatch
+	eqStrings(t, Select(in, opt), []string{"v2.1.0", "2.0.3", "v2.0.1", "1.5.0", "v1.4.0"})
+}
+
+func TestSelect_RequireFullVersion(t *testing.T) {
+	in := []string{"1", "1.2", "1.2.3", "1.2.4-rc.1", "foo"}
+
+	cases := []struct {
+		name string
+		opt  Options
+		want []string
+	}{
+		{"semver", Options{FilterSemver: true}, []string{"1", "1.2", "1.2.3", "1.2.4-rc.1"}},
+		{"semver+full", Options{FilterSemver: true, RequireFullVersion: true}, []string{"1.2.3", "1.2.4-rc.1"}},
+		{"format", Options{Format: FormatAll}, []string{"1", "1.2", "1.2.3"}},
+		{"format+full", Options{Format: FormatAll, RequireFullVersion: true}, []string{"1.2.3"}},
+		{"format xy+full", Options{Format: FormatXY, RequireFullVersion: true}, []string{}},
+		{"nogate+full", Options{RequireFullVersion: true}, []string{"1.2.3", "1.2.4-rc.1", "foo"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			eqStrings(t, Select(in, c.opt), c.want)
+		})
+	}
+}
+
+func TestSelect_ReleaseQualifiers(t *testing.T) {
+	in := []string{"1.2.3.RELEASE", "1.2.3", "1.3.0.Final", "1.4.0.ga", "1.5.0.SNAPSHOT", "1.6.RELEASE", "2.0.0-rc.1.RELEASE"}
+	opt := Options{
+		Format:            FormatXYZ,
+		ReleaseQualifiers: []string{"RELEASE", "Final", "GA"},
+	}
+
+	// qualified tags survive release gating, raw kept on output
+	eqStrings(t, Select(in, opt), []string{"1.2.3.RELEASE", "1.2.3", "1.3.0.Final", "1.4.0.ga"})
+
+	// dedup with the plain form keeps the first seen
+	opt.Deduplicate = true
+	eqStrings(t, Select(in, opt), []string{"1.2.3.RELEASE", "1.3.0.Final", "1.4.0.ga"})
+
+	opt.OutputCanonical = true
+	eqStrings(t, Select(in, opt), []string{"v1.2.3", "v1.3.0", "v1.4.0"})
+
+	// without qualifiers they are not semver
+	eqStrings(t, Select(in, Options{Format: FormatXYZ}), []string{"1.2.3"})
+}
+
+func TestSelect_ExactComponents(t *testing.T) {
+	in := []string{"1", "1.2", "1.2.3", "v2.1", "2.1.0-rc.1", "foo"}
+
+	eqStrings(t, Select(in, Options{FilterSemver: true, ExactComponents: 2}), []string{"1.2", "v2.1"})
+	eqStrings(t, Select(in, Options{FilterSemver: true, ExactComponents: 1}), []string{"1"})
+	eqStrings(t, Select(in, Options{FilterSemver: true, ExactComponents: 3}), []string{"1.2.3", "2.1.0-rc.1"})
+	eqStrings(t, Select(in, Options{FilterSemver: true, ExactComponents: 4}), []string{})
+	eqStrings(t, Select(in, Options{FilterSemver: true}), []string{"1", "1.2", "1.2.3", "v2.1", "2.1.0-rc.1"})
+}
+
+func TestSelect_MinAge(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	in := []string{"1.0.0", "1.1.0", "1.2.0", "latest"}
+	opt := Options{
+		MinAge: 24 * time.Hour,
+		Now:    now,
+		Timestamps: map[string]time.Time{
+			"1.0.0": now.Add(-72 * time.Hour),
+			"1.1.0": now.Add(-24 * time.Hour), // exactly at the boundary
+			"1.2.0": now.Add(-time.Hour),      // too fresh
+		},
+	}
+
+	// undated "latest" is kept by default
+	eqStrings(t, Select(in, opt), []string{"1.0.0", "1.1.0", "latest"})
+
+	opt.DropUndated = true
+	eqStrings(t, Select(in, opt), []string{"1.0.0", "1.1.0"})
+
+	// disabled without MinAge
+	opt.MinAge = 0
+	eqStrings(t, Select(in, opt), in)
+}
+
+func TestAggregateMajorChannels(t *testing.T) {
+	tags := []string{
+		"1.4.0", "1.5.0-rc.1", "1.3.0", // 1: release + newer rc
+		"2.1.0-beta.1", "2.1.0", // 2: rc is older than release -> release only
+		"3.0.0-alpha.1", "3.0.0-alpha.2", // 3: prerelease only
+	}
+	sem := parseRecs(t, tags)
+
+	got := aggregateMajorChannels(append([]rec{}, sem...))
+	out := make([]string, 0, len(got))
+	for _, r := range got {
+		out = append(out, r.raw)
+	}
+	eqStrings(t, out, []string{"1.4.0", "1.5.0-rc.1", "2.1.0", "3.0.0-alpha.2"})
+
+	res := Select(tags, Options{FilterSemver: true, Depth: DepthMajorChannels, Sort: SortDesc})
+	eqStrings(t, res, []string{"3.0.0-alpha.2", "2.1.0", "1.5.0-rc.1", "1.4.0"})
+}
+
+func TestTrimPreZeros(t *testing.T) {
+	cases := []struct {
+		in, want string
+		changed  bool
+	}{
+		{"1.2.3-rc.01", "1.2.3-rc.1", true},
+		{"v1.2.3-rc.00+b.01", "v1.2.3-rc.0+b.01", true},
+		{"1.2.3-007", "1.2.3-7", true},
+		{"1.2.3-rc.1", "1.2.3-rc.1", false},
+		{"1.2.3-rc.01a", "1.2.3-rc.01a", false}, // alphanumeric
+		{"1.2.3", "1.2.3", false},
+	}
+
+	for _, c := range cases {
+		got, changed := trimPreZeros(c.in)
+		if got != c.want || changed != c.changed {
+			t.Fatalf("trimPreZeros(%q)=(%q,%v), want (%q,%v)", c.in, got, changed, c.want, c.changed)
+		}
+	}
+}
+
+func TestSelect_NormalizePrereleaseNumbers(t *testing.T) {
+	in := []string{"1.2.3-rc.01", "1.2.3-rc.1", "1.2.3-rc.02", "1.2.3-rc.10"}
+
+	// without normalization the zero-padded tags are not semver
+	eqStrings(t, Select(in, Options{FilterSemver: true}), []string{"1.2.3-rc.1", "1.2.3-rc.10"})
+
+	opt := Options{FilterSemver: true, NormalizePrereleaseNumbers: true, Sort: SortAsc}
+	// rc.01 == rc.1 (tie broken by raw), rc.02 < rc.10 numerically
+	eqStrings(t, Select(in, opt), []string{"1.2.3-rc.01", "1.2.3-rc.1", "1.2.3-rc.02", "1.2.3-rc.10"})
+
+	opt.Deduplicate = true
+	opt.Sort = SortNone
+	eqStrings(t, Select(in, opt), []string{"1.2.3-rc.01", "1.2.3-rc.02", "1.2.3-rc.10"})
+}
+
+func TestSelect_PrereleaseOnly(t *testing.T) {
+	in := []string{"1.2.0", "1.2.1-rc.1", "1.2.1-rc.2", "1.3.0-beta.1", "1.3.0", "latest"}
+
+	got := Select(in, Options{PrereleaseOnly: true})
+	eqStrings(t, got, []string{"1.2.1-rc.1", "1.2.1-rc.2", "1.3.0-beta.1"})
+
+	// latest prerelease per minor
+	got = Select(in, Options{PrereleaseOnly: true, Depth: DepthMinor, Sort: SortDesc})
+	eqStrings(t, got, []string{"1.3.0-beta.1", "1.2.1-rc.2"})
+
+	// release gating takes precedence
+	got = Select(in, Options{PrereleaseOnly: true, Format: FormatXYZ})
+	eqStrings(t, got, []string{"1.2.0", "1.3.0"})
+}
+
+func TestSelect_PrereleaseChannels(t *testing.T) {
+	in := []string{"1.2.0", "1.2.0-rc.1", "1.2.0-beta.2", "1.2.1-RC2", "1.3.0-alpha", "latest"}
+
+	got := Select(in, Options{PrereleaseChannels: []string{"rc"}})
+	eqStrings(t, got, []string{"1.2.0-rc.1"})
+
+	// leading identifier only: "RC2" is not "rc"
+	got = Select(in, Options{PrereleaseChannels: []string{"Beta", "alpha", "rc2"}})
+	eqStrings(t, got, []string{"1.2.0-beta.2", "1.2.1-RC2", "1.3.0-alpha"})
+
+	// release gating runs first and drops every prerelease
+	eqStrings(t, Select(in, Options{PrereleaseChannels: []string{"rc"}, Format: FormatAll}), []string{})
+}
+
+func TestSelect_Globs(t *testing.T) {
+	in := []string{"v1.2.3", "v1.2.3-alpine", "v1.3.0-slim", "v10.0.0", "2.0.0", "latest"}
+
+	got := Select(in, Options{IncludeGlob: []string{"v1.*"}})
+	eqStrings(t, got, []string{"v1.2.3", "v1.2.3-alpine", "v1.3.0-slim"})
+
+	got = Select(in, Options{IncludeGlob: []string{"v1.*", "2.*"}, ExcludeGlob: []string{"*-alpine", "*-slim"}})
+	eqStrings(t, got, []string{"v1.2.3", "2.0.0"})
+
+	// ANDed with regex gates
+	got = Select(in, Options{IncludeGlob: []string{"v*"}, Include: regexp.MustCompile(`^v1\.`)})
+	eqStrings(t, got, []string{"v1.2.3", "v1.2.3-alpine", "v1.3.0-slim"})
+
+	// empty slices are a no-op
+	eqStrings(t, Select(in, Options{IncludeGlob: []string{}}), in)
+}
+
+func TestSelect_GlobErrors(t *testing.T) {
+	in := []string{"1.0.0", "1.0.0-alpine"}
+	opt := Options{ExcludeGlob: []string{"[abc", "*-alpine"}}
+
+	if _, err := SelectErr(in, opt); err == nil {
+		t.Fatalf("want glob error")
+	}
+
+	// invalid patterns never match, valid ones still apply
+	eqStrings(t, Select(in, opt), []string{"1.0.0"})
+	eqStrings(t, Select(in, Options{IncludeGlob: []string{"[abc"}}), []string{})
+}
+
+func TestSelect_Affixes(t *testing.T) {
+	in := []string{"1.2.3", "1.2.3-alpine", "1.2.3-SLIM", "nightly-1", "Nightly-2", "1.3.0-slim"}
+
+	got := Select(in, Options{ExcludeSuffixes: []string{"-alpine", "-slim"}, ExcludePrefixes: []string{"nightly-"}})
+	eqStrings(t, got, []string{"1.2.3", "1.2.3-SLIM", "Nightly-2"})
+
+	got = Select(in, Options{ExcludeSuffixes: []string{"-alpine", "-slim"}, ExcludePrefixes: []string{"nightly-"}, AffixIgnoreCase: true})
+	eqStrings(t, got, []string{"1.2.3"})
+
+	got = Select(in, Options{IncludeSuffixes: []string{"-slim"}, AffixIgnoreCase: true})
+	eqStrings(t, got, []string{"1.2.3-SLIM", "1.3.0-slim"})
+}
+
+func TestSelect_KeepPerGroup(t *testing.T) {
+	in := []string{"1.2.0", "1.2.1", "v1.2.1", "1.2.2", "1.2.3", "1.3.0", "2.0.0", "2.1.0"}
+
+	// ties (1.2.1 vs v1.2.1) take separate slots, in input order
+	got := Select(in, Options{FilterSemver: true, Depth: DepthMinor, KeepPerGroup: 4})
+	eqStrings(t, got, []string{"1.2.3", "1.2.2", "1.2.1", "v1.2.1", "1.3.0", "2.0.0", "2.1.0"})
+
+	got = Select(in, Options{FilterSemver: true, Deduplicate: true, Depth: DepthMinor, KeepPerGroup: 3, Sort: SortDesc})
+	eqStrings(t, got, []string{"2.1.0", "2.0.0", "1.3.0", "1.2.3", "1.2.2", "1.2.1"})
+
+	// groups smaller than N are kept whole
+	got = Select(in, Options{FilterSemver: true, Depth: DepthMajor, KeepPerGroup: 5, Deduplicate: true})
+	eqStrings(t, got, []string{"1.3.0", "1.2.3", "1.2.2", "1.2.1", "1.2.0", "2.1.0", "2.0.0"})
+
+	got = Select(in, Options{FilterSemver: true, Depth: DepthLatest, KeepPerGroup: 2})
+	eqStrings(t, got, []string{"2.1.0", "2.0.0"})
+
+	// no-op for DepthPatch
+	eqStrings(t, Select(in, Options{FilterSemver: true, Depth: DepthPatch, KeepPerGroup: 2}), in)
+}
+
+func TestSelect_DepthNone(t *testing.T) {
+	in := []string{"1.3.0", "v1.2.0", "1.2.0", "latest", "1.10.0"}
+
+	// semver keep input order (non-semver follow), dedup keeps the first alias in place
+	got := Select(in, Options{Depth: DepthNone, Deduplicate: true})
+	eqStrings(t, got, []string{"1.3.0", "v1.2.0", "1.10.0", "latest"})
+
+	if ParseDepth("none") != DepthNone || ParseDepth("n") != DepthNone {
+		t.Fatalf("ParseDepth must recognize none/n")
+	}
+}
+
+func TestSelect_FallbackPrerelease(t *testing.T) {
+	in := []string{"1.0.0", "1.1.0-rc.1", "2.0.0-beta.1", "2.0.0-rc.1", "latest"}
+
+	// release present: it wins over the newer prerelease
+	// only prereleases: the latest one is kept
+	got := Select(in, Options{FilterSemver: true, Depth: DepthMajor, FallbackPrerelease: true})
+	eqStrings(t, got, []string{"1.0.0", "2.0.0-rc.1"})
+
+	// default: highest version wins
+	got = Select(in, Options{FilterSemver: true, Depth: DepthMajor})
+	eqStrings(t, got, []string{"1.1.0-rc.1", "2.0.0-rc.1"})
+
+	got = Select(in, Options{FilterSemver: true, Depth: DepthMinor, FallbackPrerelease: true})
+	eqStrings(t, got, []string{"1.0.0", "1.1.0-rc.1", "2.0.0-rc.1"})
+
+	got = Select(in, Options{FilterSemver: true, Depth: DepthLatest, FallbackPrerelease: true})
+	eqStrings(t, got, []string{"1.0.0"})
+
+	// release gating keeps the prerelease of a major without releases
+	got = Select([]string{"v1.0.0", "v2.0.0-rc.1"}, Options{Depth: DepthMajor, FallbackPrerelease: true, Format: FormatAll})
+	eqStrings(t, got, []string{"v1.0.0", "v2.0.0-rc.1"})
+
+	opt := DefaultOptions()
+	opt.FallbackPrerelease = true
+	got = Select(in, opt)
+	eqStrings(t, got, []string{"2.0.0-rc.1", "1.1.0-rc.1", "1.0.0"})
+
+	opt.Depth = DepthMajor
+	got = Select(in, opt)
+	eqStrings(t, got, []string{"2.0.0-rc.1", "1.0.0"})
+
+	// without a fallback depth release gating still drops prereleases
+	opt.Depth = DepthNone
+	got = Select(in, opt)
+	eqStrings(t, got, []string{"1.0.0"})
+}
+
+func TestSelect_Ranges(t *testing.T) {
+	in := []string{"1.0.0", "1.2.0", "1.4.0", "1.5.0", "2.0.0", "2.5.0", "3.0.0"}
+
+	// overlapping ranges do not duplicate
+	got := Select(in, Options{Ranges: []Range{{Min: "1.2", Max: "1.5"}, {Min: "1.4", Max: "2"}}})
+	eqStrings(t, got, []string{"1.2.0", "1.4.0", "1.5.0", "2.0.0"})
+
+	// adjacent at 2.0.0: exclusive on one side keeps the seam once
+	got = Select(in, Options{Ranges: []Range{{Min: "1.5", Max: "2", MaxExclusive: true}, {Min: "2", Max: "2.5"}}})
+	eqStrings(t, got, []string{"1.5.0", "2.0.0", "2.5.0"})
+
+	// both exclusive at the seam drop it
+	got = Select(in, Options{Ranges: []Range{{Min: "1.5", Max: "2", MaxExclusive: true}, {Min: "2", MinExclusive: true, Max: "2.5"}}})
+	eqStrings(t, got, []string{"1.5.0", "2.5.0"})
+
+	// Range is the first entry
+	got = Select(in, Options{Range: Range{Max: "1.0"}, Ranges: []Range{{Min: "3"}}})
+	eqStrings(t, got, []string{"1.0.0", "3.0.0"})
+}
+
+func TestApplyRange_PrereleaseAtMax(t *testing.T) {
+	sem := parseRecs(t, []string{"1.2.2", "1.2.3-rc.1", "1.2.3", "1.2.4-rc.1"})
+
+	cases := []struct {
+		r    Range
+		want []string
+	}{
+		{Range{Max: "1.2.3"}, []string{"1.2.2", "1.2.3-rc.1", "1.2.3"}},
+		{Range{Max: "1.2.3", ExcludePrereleaseAtMax: true}, []string{"1.2.2", "1.2.3"}},
+		{Range{Max: "1.2.3", MaxExclusive: true}, []string{"1.2.2", "1.2.3-rc.1"}},
+		{Range{Max: "1.2.3", MaxExclusive: true, ExcludePrereleaseAtMax: true}, []string{"1.2.2"}},
+		// prerelease ceiling: plain precedence
+		{Range{Max: "1.2.3-rc.1"}, []string{"1.2.2", "1.2.3-rc.1"}},
+	}
+
+	for _, c := range cases {
+		got := applyRange(append([]rec{}, sem...), c.r)
+		out := make([]string, 0, len(got))
+		for _, r := range got {
+			out = append(out, r.raw)
+		}
+		eqStrings(t, out, c.want)
+	}
+
+	// default keeps the prereleases below a shorthand exclusive ceiling
+	in := []string{"1.9.0", "2.0.0-rc.1", "2.0.0"}
+	eqStrings(t, Select(in, Options{Range: Range{Max: "2", MaxExclusive: true}}), []string{"1.9.0", "2.0.0-rc.1"})
+	eqStrings(t, Select(in, Options{Range: Range{Max: "2", MaxExclusive: true, ExcludePrereleaseAtMax: true}}), []string{"1.9.0"})
+}
+
+func TestSelect_DedupPrefer(t *testing.T) {
+	in := []string{"v1.2.3", "1.2.3", "1.2.3+build9", "1.2.3+build10", "v1.2.3+b.2", "1.3", "v1.3.0", "1.3.0"}
+	run := func(p DedupPrefer) []string {
+		return Select(in, Options{FilterSemver: true, Deduplicate: true, DedupPrefer: p})
+	}
+
+	eqStrings(t, run(PreferFirstSeen), []string{"v1.2.3", "1.3"})
+	eqStrings(t, run(PreferCanonical), []string{"v1.2.3", "v1.3.0"})
+	eqStrings(t, run(PreferShortest), []string{"1.2.3", "1.3"})
+	// alphanumeric identifiers compare lexically: "build9" > "build10" > "b.2"
+	eqStrings(t, run(PreferHighestBuild), []string{"1.2.3+build9", "1.3"})
+}
+
+func TestCompareBuild(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "1", -1},
+		{"2", "10", -1},
+		{"010", "9", 1},
+		{"1", "a", -1},
+		{"b.1", "b.1.1", -1},
+		{"b.2", "b.10", -1},
+		{"sha.abc", "sha.abd", -1},
+	}
+
+	for _, c := range cases {
+		if got := compareBuild(c.a, c.b); got != c.want {
+			t.Fatalf("compareBuild(%q,%q)=%d, want %d", c.a, c.b, got, c.want)
+		}
+		if got := compareBuild(c.b, c.a); got != -c.want {
+			t.Fatalf("compareBuild(%q,%q)=%d, want %d", c.b, c.a, got, -c.want)
+		}
+	}
+
+	if ParseDedupPrefer("Highest-Build") != PreferHighestBuild || PreferCanonical.String() != "canonical" {
+		t.Fatalf("DedupPrefer parse/string mismatch")
+	}
+}
+
+func TestSelect_DeduplicateAliases(t *testing.T) {
+	got := Select([]string{"1.2", "v1.2.0", "1.2.0"}, Options{Deduplicate: true})
+	eqStrings(t, got, []string{"1.2"})
+
+	// no canonical output or SemVer gating needed; non-semver pass through
+	got = Select([]string{"1", "v1", "1.0.0", "latest", "v1.0.0+b"}, Options{Deduplicate: true})
+	eqStrings(t, got, []string{"1", "latest"})
+}
+
+func TestSortSemver_TieBreak(t *testing.T) {
+	in := []string{"1.2.3+build", "v1.2.3", "1.2.3", "1.0.0"}
+
+	run := func(sort SortMode, tb TieBreak) []string {
+		return Select(in, Options{FilterSemver: true, Sort: sort, TieBreak: tb})
+	}
+
+	eqStrings(t, run(SortAsc, TieLexical), []string{"1.0.0", "1.2.3", "1.2.3+build", "v1.2.3"})
+	eqStrings(t, run(SortDesc, TieLexical), []string{"v1.2.3", "1.2.3+build", "1.2.3", "1.0.0"})
+	eqStrings(t, run(SortDesc, TieShortest), []string{"1.2.3", "v1.2.3", "1.2.3+build", "1.0.0"})
+	eqStrings(t, run(SortDesc, TieInputOrder), []string{"1.2.3+build", "v1.2.3", "1.2.3", "1.0.0"})
+
+	if ParseTieBreak("length") != TieShortest || TieInputOrder.String() != "input" {
+		t.Fatalf("TieBreak parse/string mismatch")
+	}
+}
+
+func TestSortNatural(t *testing.T) {
+	in := []string{"img9", "img10", "img1", "img010a", "img", "b2", "a10"}
+	sortNatural(in)
+	eqStrings(t, in, []string{"a10", "b2", "img", "img1", "img9", "img10", "img010a"})
+
+	got := Select([]string{"img9", "img10", "img1"}, Options{Sort: SortNatural})
+	eqStrings(t, got, []string{"img1", "img9", "img10"})
+}
+
+func TestSelect_SortReverse(t *testing.T) {
+	got := Select([]string{"b", "a", "c"}, Options{Sort: SortReverse})
+	eqStrings(t, got, []string{"c", "a", "b"})
+
+	// semver and non-semver parts are reversed independently
+	got = Select([]string{"1.0.0", "edge", "2.0.0", "latest", "1.5.0"}, Options{Sort: SortReverse})
+	eqStrings(t, got, []string{"1.5.0", "2.0.0", "1.0.0", "latest", "edge"})
+}
+
+func TestEpochAware(t *testing.T) {
+	t.Parallel()
+
+	opt := Options{EpochAware: true, Sort: SortDesc}
+
+	// epoch beats any core, revision orders equal cores
+	in := []string{"1.2.3-9", "1:1.2.3-2", "1.2.3-10", "1.2.3", "2.0.0", "1.2.3-rc.1", "latest"}
+	eqStrings(t, Select(in, opt), []string{"1:1.2.3-2", "2.0.0", "1.2.3-10", "1.2.3-9", "1.2.3", "1.2.3-rc.1", "latest"})
+
+	// without it "1.2.3-9" stays a prerelease and "1:1.2.3-2" is not SemVer
+	eqStrings(t, Select(in, Options{Sort: SortDesc}), []string{"2.0.0", "1.2.3", "1.2.3-rc.1", "1.2.3-10", "1.2.3-9", "latest", "1:1.2.3-2"})
+
+	// revisions are releases and distinct versions; aggregation compares them too
+	opt = Options{EpochAware: true, Deduplicate: true, Format: FormatAll, Depth: DepthMinor}
+	eqStrings(t, Select([]string{"1.2.3-1", "1.2.3-2", "v1.2.3-2", "1.2.4-rc.1"}, opt), []string{"1.2.3-2"})
+
+	opt = Options{EpochAware: true, Deduplicate: true, Sort: SortAsc, OutputCanonical: true}
+	eqStrings(t, Select([]string{"2:3.4-1", "1.2.3-1", "v1.2.3-1"}, opt), []string{"v1.2.3-1", "2:v3.4.0-1"})
+
+	// malformed epoch/revision fall back to the usual parse
+	opt = Options{EpochAware: true, Sort: SortDesc}
+	eqStrings(t, Select([]string{"x:1.2.3", "1.2.3-beta", "-1:1.0.0", "1.2.3-1"}, opt), []string{"1.2.3-1", "1.2.3-beta", "x:1.2.3", "-1:1.0.0"})
+}
+
+func TestCalVer(t *testing.T) {
+	t.Parallel()
+
+	// a year of monthly builds, a few patches each, newest input last
+	var in []string
+	for m := 1; m <= 12; m++ {
+		for p := 0; p < 3; p++ {
+			in = append(in, fmt.Sprintf("2024.%02d.%d", m, p))
+		}
+	}
+	in = append(in, "2023.12.05", "2025.01.01-rc.1", "2024.13.1", "1.2.3", "latest")
+
+	opt := Options{CalVer: true, FilterSemver: true, Depth: ParseDepth("year"), Sort: SortDesc}
+	eqStrings(t, Select(in, opt), []string{"2025.01.01-rc.1", "2024.12.2", "2023.12.05"})
+
+	opt.Depth = ParseDepth("month")
+	opt.Limit = 4
+	eqStrings(t, Select(in, opt), []string{"2025.01.01-rc.1", "2024.12.2", "2024.11.2", "2024.10.2"})
+
+	opt = Options{CalVer: true, Format: FormatXYZ, Range: Range{Min: "2024.3", Max: "2024.4"}, Sort: SortAsc}
+	eqStrings(t, Select(in, opt), []string{"2024.03.0", "2024.03.1", "2024.03.2", "2024.04.0"})
+
+	// non-dates stay plain tags without FilterSemver
+	got := Select([]string{"2024.13.1", "1.2.3", "2024.01", "2024"}, Options{CalVer: true, Sort: SortDesc})
+	eqStrings(t, got, []string{"2024.01", "2024", "2024.13.1", "1.2.3"})
+}
+
+func TestTrimCoreZeros(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"2024.03.01":       "2024.3.1",
+		"v2024.03.00-rc.1": "v2024.3.0-rc.1",
+		"2024.3.1":         "2024.3.1",
+		"2024.03.1+b.01":   "2024.3.1+b.01",
+	}
+	for in, want := range cases {
+		if got, _ := trimCoreZeros(in); got != want {
+			t.Errorf("trimCoreZeros(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSelect_CompareBuild(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"1.2.3+build.10", "1.2.3+build.2", "1.2.3", "1.2.3+build.x", "1.2.3+build.2.1", "1.2.4", "1.2.3+abc"}
+
+	// numeric below alphanumeric, longer wins on a common prefix, no build lowest
+	opt := Options{Sort: SortAsc, CompareBuild: true}
+	eqStrings(t, Select(in, opt), []string{"1.2.3", "1.2.3+abc", "1.2.3+build.2", "1.2.3+build.2.1", "1.2.3+build.10", "1.2.3+build.x", "1.2.4"})
+
+	opt.Sort = SortDesc
+	eqStrings(t, Select(in, opt), []string{"1.2.4", "1.2.3+build.x", "1.2.3+build.10", "1.2.3+build.2.1", "1.2.3+build.2", "1.2.3+abc", "1.2.3"})
+
+	// top-K path agrees
+	opt.Limit = 3
+	eqStrings(t, Select(in, opt), []string{"1.2.4", "1.2.3+build.x", "1.2.3+build.10"})
+
+	// off: lexical tie-break on the raw tag
+	eqStrings(t, Select(in, Options{Sort: SortAsc}), []string{"1.2.3", "1.2.3+abc", "1.2.3+build.10", "1.2.3+build.2", "1.2.3+build.2.1", "1.2.3+build.x", "1.2.4"})
+}
+
+func TestMatchCanonical(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"1.0", "v1.0.1", "1.1.0+b1", "v2.0", "2.1.0-rc.1", "latest", "v3.0.0"}
+
+	// minor 0 regardless of 'v' or shorthand
+	opt := Options{MatchCanonical: true, Exclude: regexp.MustCompile(`^v\d+\.0\.`)}
+	eqStrings(t, Select(in, opt), []string{"1.1.0+b1", "2.1.0-rc.1", "latest"})
+
+	// raw matching misses the shorthand and unprefixed forms
+	opt.MatchCanonical = false
+	eqStrings(t, Select(in, opt), []string{"1.0", "1.1.0+b1", "v2.0", "2.1.0-rc.1", "latest"})
+
+	// non-semver tags are matched raw; IncludeAny ORs as usual
+	opt = Options{MatchCanonical: true, IncludeAny: []*regexp.Regexp{regexp.MustCompile(`^v1\.`), regexp.MustCompile(`^lat`)}}
+	eqStrings(t, Select(in, opt), []string{"1.0", "v1.0.1", "1.1.0+b1", "latest"})
+	eqStrings(t, Unparseable(in, opt), []string{"latest"})
+
+	// string-only path
+	opt = Options{MatchCanonical: true, Exclude: regexp.MustCompile(`^edge$`), Sort: SortAsc}
+	eqStrings(t, Select([]string{"latest", "edge", "beta"}, opt), []string{"beta", "latest"})
+}
+
+func TestAllowBuildInRelease(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"1.2.3+build.1", "1.2.3-rc.1", "1.2.3-rc.1+build.2", "1.2.2", "v1.2"}
+
+	opt := Options{Format: FormatAll, AllowBuildInRelease: true}
+	eqStrings(t, Select(in, opt), []string{"1.2.3+build.1", "1.2.2", "v1.2"})
+
+	opt.AllowBuildInRelease = false
+	eqStrings(t, Select(in, opt), []string{"1.2.2", "v1.2"})
+
+	// the form mask still applies, build aliases still collapse
+	opt = Options{Format: FormatXYZ, AllowBuildInRelease: true, Deduplicate: true}
+	eqStrings(t, Select(append(in, "1.2.3"), opt), []string{"1.2.3+build.1", "1.2.2"})
+}
+
+func TestFloatingTags(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"1.0.0", "Latest", "foo", "2.0.0-rc.1", "2.0.0", "edge"}
+
+	opt := Options{FilterSemver: true, FloatingTags: []string{"latest"}, Sort: SortDesc}
+	eqStrings(t, Select(in, opt), []string{"2.0.0", "2.0.0-rc.1", "1.0.0", "Latest"})
+
+	opt = Options{Format: FormatAll, FloatingTags: []string{"latest", "edge"}, NonSemverPlacement: NonSemverPrepend}
+	eqStrings(t, Select(in, opt), []string{"Latest", "edge", "1.0.0", "2.0.0"})
+
+	// no SemVer at all
+	opt = Options{FilterSemver: true, FloatingTags: []string{"latest"}}
+	eqStrings(t, Select([]string{"foo", "latest"}, opt), []string{"latest"})
+	eqStrings(t, Select([]string{"foo"}, opt), []string{})
+
+	// raw gates still apply
+	opt.Exclude = regexp.MustCompile(`^latest$`)
+	eqStrings(t, Select([]string{"1.0.0", "latest"}, opt), []string{"1.0.0"})
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"V1.2.3", "v1.3.0-RC.1", "Latest", "1.4.0", "V2.0.0-Alpine"}
+
+	// regexes see the lowercased tag, output keeps the original case
+	opt := Options{
+		CaseInsensitive: true,
+		Include:         regexp.MustCompile(`^(v|latest)`),
+		ExcludeAny:      []*regexp.Regexp{regexp.MustCompile(`-alpine$`), regexp.MustCompile(`-rc\.`)},
+	}
+	eqStrings(t, Select(in, opt), []string{"V1.2.3", "Latest"})
+
+	opt.CaseInsensitive = false
+	eqStrings(t, Select(in, opt), []string{"v1.3.0-RC.1"})
+
+	// "Latest" matched by a lowercase floating tag, "V1.2.3" by PrefixV
+	opt = Options{
+		CaseInsensitive: true,
+		VPrefix:         PrefixV,
+		FilterSemver:    true,
+		FloatingTags:    []string{"latest"},
+		ExcludeGlob:     []string{"*-alpine"},
+	}
+	eqStrings(t, Select(in, opt), []string{"V1.2.3", "v1.3.0-RC.1"})
+
+	opt.VPrefix = PrefixAny
+	eqStrings(t, Select(in, opt), []string{"V1.2.3", "v1.3.0-RC.1", "1.4.0", "Latest"})
+
+	// signature tags in upper case
+	sig := "SHA256-" + strings.Repeat("AB", 32) + ".SIG"
+	eqStrings(t, Select([]string{sig, "1.0.0"}, Options{CaseInsensitive: true, ExcludeSignatures: true}), []string{"1.0.0"})
+}
@@ -0,0 +1,119 @@
+package rats
+
+import (
+	"strconv"
+
+	"github.com/woozymasta/semver"
+)
+
+// Group is a bucket of selected tags sharing a major or a (major, minor) series.
+type Group struct {
+	// Key is "MAJOR" for major groups and "MAJOR.MINOR" for minor groups.
+	Key string
+
+	// Tags are the rendered members of the group.
+	Tags []string
+
+	// Versions are the parsed members (Original set), parallel to Tags.
+	Versions []semver.Semver
+
+	// Major series of the group.
+	Major int
+
+	// Minor series of the group; always 0 for major groups.
+	Minor int
+}
+
+// SelectGrouped runs the Select pipeline (including Limit) and buckets the
+// SemVer results by major, or by (major, minor) when by is DepthMinor.
+// Non-semver tags are omitted.
+//
+// Groups follow the order of their first member in the Select output, so
+// with SortDesc the newest series comes first. Members keep the Select
+// order unless opt.WithinGroupSort is SortAsc/SortDesc.
+func SelectGrouped(in []string, by Depth, opt Options) []Group {
+	opt = opt.normalized()
+
+	rs := selectLimited(in, opt)
+
+	type bucket struct {
+		g    Group
+		recs []rec
+	}
+
+	idx := make(map[[2]int]int, 16)
+	buckets := make([]bucket, 0, 16)
+
+	for _, r := range rs {
+		if !r.ver.Valid {
+			continue
+		}
+
+		k := [2]int{r.ver.Major, 0}
+		if by == DepthMinor {
+			k[1] = r.ver.Minor
+		}
+
+		i, ok := idx[k]
+		if !ok {
+			i = len(buckets)
+			idx[k] = i
+			buckets = append(buckets, bucket{g: Group{Key: groupKey(k[0], k[1], by), Major: k[0], Minor: k[1]}})
+		}
+
+		buckets[i].recs = append(buckets[i].recs, r)
+	}
+
+	out := make([]Group, 0, len(buckets))
+	for _, b := range buckets {
+		switch opt.WithinGroupSort {
+		case SortAsc:
+			sortSemver(b.recs, true, opt.sortTie())
+		case SortDesc:
+			sortSemver(b.recs, false, opt.sortTie())
+		}
+
+		b.g.Tags = renderRecs(b.recs, opt)
+		b.g.Versions = make([]semver.Semver, 0, len(b.recs))
+		for _, r := range b.recs {
+			b.g.Versions = append(b.g.Versions, r.ver)
+		}
+		out = append(out, b.g)
+	}
+
+	return out
+}
+
+// GroupByMajor buckets every SemVer tag selected by opt by major series.
+// Unlike DepthMajor, which keeps only the latest version, each group holds
+// all its members (use opt.Depth to thin them out first).
+//
+// Groups are ordered newest major first and members newest first; an
+// explicit opt.Sort (or opt.WithinGroupSort) overrides that order.
+// Non-semver tags are omitted.
+func GroupByMajor(in []string, opt Options) []Group {
+	return groupBy(in, DepthMajor, opt)
+}
+
+// GroupByMinor is GroupByMajor for (major, minor) series.
+func GroupByMinor(in []string, opt Options) []Group {
+	return groupBy(in, DepthMinor, opt)
+}
+
+// groupBy is SelectGrouped defaulting to newest-first order.
+func groupBy(in []string, by Depth, opt Options) []Group {
+	if opt.Sort == SortNone {
+		opt.Sort = SortDesc
+	}
+
+	return SelectGrouped(in, by, opt)
+}
+
+// groupKey formats a group key as "MAJOR" or "MAJOR.MINOR".
+func groupKey(major, minor int, by Depth) string {
+	if by == DepthMinor {
+		return strconv.Itoa(major) + "." + strconv.Itoa(minor)
+	}
+
+	return strconv.Itoa(major)
+}
@@ -0,0 +1,58 @@
+package rats
+
+import "testing"
+
+func TestSelectGrouped_WithinGroupSort(t *testing.T) {
+	in := []string{"1.0.0", "2.0.1", "1.0.2", "2.0.0", "1.0.1", "2.1.0", "latest"}
+	opt := Options{Sort: SortDesc, WithinGroupSort: SortAsc}
+
+	got := SelectGrouped(in, DepthMajor, opt)
+	if len(got) != 2 {
+		t.Fatalf("groups=%d, want 2: %+v", len(got), got)
+	}
+
+	if got[0].Key != "2" || got[0].Major != 2 {
+		t.Fatalf("first group=%+v, want major 2", got[0])
+	}
+	eqStrings(t, got[0].Tags, []string{"2.0.0", "2.0.1", "2.1.0"})
+
+	if got[1].Key != "1" {
+		t.Fatalf("second group=%+v, want major 1", got[1])
+	}
+	eqStrings(t, got[1].Tags, []string{"1.0.0", "1.0.1", "1.0.2"})
+
+	// no WithinGroupSort -> members follow Sort
+	opt.WithinGroupSort = SortNone
+	got = SelectGrouped(in, DepthMajor, opt)
+	eqStrings(t, got[0].Tags, []string{"2.1.0", "2.0.1", "2.0.0"})
+}
+
+func TestSelectGrouped_Minor(t *testing.T) {
+	in := []string{"1.2.0", "1.3.1", "1.2.1", "1.3.0"}
+
+	got := SelectGrouped(in, DepthMinor, Options{Sort: SortAsc})
+	if len(got) != 2 || got[0].Key != "1.2" || got[1].Key != "1.3" || got[1].Minor != 3 {
+		t.Fatalf("got %+v", got)
+	}
+	eqStrings(t, got[0].Tags, []string{"1.2.0", "1.2.1"})
+	eqStrings(t, got[1].Tags, []string{"1.3.0", "1.3.1"})
+}
+
+func TestGroupByMajorMinor(t *testing.T) {
+	in := []string{"1.0.0", "2.0.1", "v1.1.0", "2.0.0", "1.0.1", "latest"}
+
+	got := GroupByMajor(in, Options{})
+	if len(got) != 2 || got[0].Major != 2 || got[1].Major != 1 {
+		t.Fatalf("major groups: %+v", got)
+	}
+	eqStrings(t, got[1].Tags, []string{"v1.1.0", "1.0.1", "1.0.0"})
+	if len(got[1].Versions) != 3 || got[1].Versions[0].Original != "v1.1.0" || got[1].Versions[2].Patch != 0 {
+		t.Fatalf("versions: %+v", got[1].Versions)
+	}
+
+	got = GroupByMinor(in, Options{Sort: SortAsc})
+	if len(got) != 3 || got[0].Key != "1.0" || got[1].Key != "1.1" || got[2].Key != "2.0" {
+		t.Fatalf("minor groups: %+v", got)
+	}
+	eqStrings(t, got[0].Tags, []string{"1.0.0", "1.0.1"})
+}
@@ -0,0 +1,184 @@
+package rats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileName is the conventional name of an ignore file looked up by the CLI.
+const IgnoreFileName = ".ratsignore"
+
+// IgnoreList is an ordered list of .ratsignore rules.
+//
+// File format (one rule per line):
+//
+//	# comment          lines starting with '#' are ignored, as are blank lines
+//	*-alpine           glob matched against the whole tag ('*', '?', '[...]')
+//	/^sha256-/         regexp (unanchored) when wrapped in slashes
+//	!1.2.3-alpine      leading '!' re-includes a tag dropped by an earlier rule
+//	\#literal, \!tag   backslash escapes a leading '#' or '!'
+//
+// Like .gitignore, the last matching rule wins.
+type IgnoreList struct {
+	rules []ignoreRule
+}
+
+// ignoreRule is a single compiled line of an ignore file.
+type ignoreRule struct {
+	re     *regexp.Regexp
+	negate bool
+}
+
+// ParseIgnore reads rules from r. Errors include the offending line number.
+func ParseIgnore(r io.Reader) (*IgnoreList, error) {
+	l := &IgnoreList{}
+	sc := bufio.NewScanner(r)
+
+	n := 0
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		negate := false
+		switch {
+		case line[0] == '!':
+			negate = true
+			line = line[1:]
+		case line[0] == '\\' && len(line) > 1 && (line[1] == '#' || line[1] == '!'):
+			line = line[1:]
+		}
+
+		if line == "" {
+			continue
+		}
+
+		re, err := compileIgnorePattern(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		l.rules = append(l.rules, ignoreRule{re: re, negate: negate})
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// LoadIgnore reads and parses an ignore file from path.
+func LoadIgnore(path string) (*IgnoreList, error) {
+	f, err := os.Open(path) // #nosec G304 -- path is provided by the caller
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	l, err := ParseIgnore(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return l, nil
+}
+
+// Len returns the number of rules.
+func (l *IgnoreList) Len() int {
+	if l == nil {
+		return 0
+	}
+
+	return len(l.rules)
+}
+
+// Ignored reports whether tag is excluded by the rules. A nil list ignores nothing.
+func (l *IgnoreList) Ignored(tag string) bool {
+	if l == nil {
+		return false
+	}
+
+	ignored := false
+	for _, r := range l.rules {
+		// a rule can only flip the current state
+		if r.negate != ignored {
+			continue
+		}
+
+		if r.re.MatchString(tag) {
+			ignored = !r.negate
+		}
+	}
+
+	return ignored
+}
+
+// compileIgnorePattern compiles "/regexp/" as-is and anything else as a glob.
+func compileIgnorePattern(p string) (*regexp.Regexp, error) {
+	if len(p) >= 2 && p[0] == '/' && p[len(p)-1] == '/' {
+		return regexp.Compile(p[1 : len(p)-1])
+	}
+
+	return compileGlob(p)
+}
+
+// compileGlob converts a shell glob into an anchored regexp.
+// Supported: '*' (any run), '?' (any single char), '[...]' / '[!...]' classes
+// and '\' escapes. Unlike path.Match, '*' also matches '/'.
+func compileGlob(p string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.Grow(len(p) + 8)
+	b.WriteByte('^')
+
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch c {
+		case '*':
+			b.WriteString(".*")
+
+		case '?':
+			b.WriteByte('.')
+
+		case '\\':
+			if i+1 >= len(p) {
+				return nil, fmt.Errorf("glob %q: trailing escape", p)
+			}
+			i++
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+
+		case '[':
+			end := strings.IndexByte(p[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("glob %q: unterminated character class", p)
+			}
+
+			class := p[i+1 : i+1+end]
+			if class == "" || class == "!" || class == "^" {
+				return nil, fmt.Errorf("glob %q: empty character class", p)
+			}
+
+			b.WriteByte('[')
+			if class[0] == '!' || class[0] == '^' {
+				b.WriteByte('^')
+				class = class[1:]
+			}
+			b.WriteString(strings.ReplaceAll(class, `\`, `\\`))
+			b.WriteByte(']')
+			i += end + 1
+
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+
+	b.WriteByte('$')
+
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,116 @@
+package rats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseIgnore(t *testing.T) {
+	src := `
+# drop platform variants
+*-alpine
+*-windows
+
+/^nightly-/
+!1.2.3-alpine
+\#hash
+`
+	l, err := ParseIgnore(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ParseIgnore: %v", err)
+	}
+
+	if l.Len() != 5 {
+		t.Fatalf("Len()=%d, want 5", l.Len())
+	}
+
+	cases := map[string]bool{
+		"1.2.4-alpine":     true,
+		"1.2.3-alpine":     false, // re-included
+		"2.0-windows":      true,
+		"nightly-20240101": true,
+		"my-nightly-1":     false,
+		"#hash":            true,
+		"1.2.3":            false,
+	}
+
+	for tag, want := range cases {
+		if got := l.Ignored(tag); got != want {
+			t.Fatalf("Ignored(%q)=%v, want %v", tag, got, want)
+		}
+	}
+}
+
+func TestParseIgnore_LastMatchWins(t *testing.T) {
+	l, err := ParseIgnore(strings.NewReader("*-rc*\n!1.*\n1.2.*"))
+	if err != nil {
+		t.Fatalf("ParseIgnore: %v", err)
+	}
+
+	cases := map[string]bool{
+		"2.0.0-rc.1": true,
+		"1.1.0-rc.1": false,
+		"1.2.0-rc.1": true,
+		"1.2.0":      true,
+	}
+
+	for tag, want := range cases {
+		if got := l.Ignored(tag); got != want {
+			t.Fatalf("Ignored(%q)=%v, want %v", tag, got, want)
+		}
+	}
+}
+
+func TestParseIgnore_Errors(t *testing.T) {
+	bad := []string{
+		"ok\n[abc",    // unterminated class
+		"/(unclosed/", // bad regexp
+		`foo\`,        // trailing escape
+	}
+
+	for _, src := range bad {
+		if _, err := ParseIgnore(strings.NewReader(src)); err == nil {
+			t.Fatalf("ParseIgnore(%q): want error", src)
+		}
+	}
+}
+
+func TestCompileGlob(t *testing.T) {
+	cases := []struct {
+		glob string
+		tag  string
+		want bool
+	}{
+		{"v1.*", "v1.2.3", true},
+		{"v1.*", "v10.2.3", false},
+		{"1.?", "1.2", true},
+		{"1.?", "1.22", false},
+		{"[0-9]*-slim", "3.11-slim", true},
+		{"[!0-9]*", "latest", true},
+		{"[!0-9]*", "1.2", false},
+		{`1.2.\*`, "1.2.*", true},
+		{`1.2.\*`, "1.2.3", false},
+	}
+
+	for _, c := range cases {
+		re, err := compileGlob(c.glob)
+		if err != nil {
+			t.Fatalf("compileGlob(%q): %v", c.glob, err)
+		}
+
+		if got := re.MatchString(c.tag); got != c.want {
+			t.Fatalf("glob %q match %q = %v, want %v", c.glob, c.tag, got, c.want)
+		}
+	}
+}
+
+func TestSelect_Ignore(t *testing.T) {
+	l, err := ParseIgnore(strings.NewReader("*-alpine\n!2.*"))
+	if err != nil {
+		t.Fatalf("ParseIgnore: %v", err)
+	}
+
+	in := []string{"1.0.0", "1.0.0-alpine", "2.0.0-alpine", "latest"}
+	got := Select(in, Options{Ignore: l})
+	eqStrings(t, got, []string{"1.0.0", "2.0.0-alpine", "latest"})
+}
@@ -0,0 +1,25 @@
+package rats
+
+import (
+	"context"
+	"fmt"
+)
+
+// TagLister lists the tags of a repository. Implement it on top of any
+// registry client (crane, go-containerregistry, a registry HTTP API, a mock);
+// the package itself never talks to the network.
+type TagLister interface {
+	ListTags(ctx context.Context, repo string) ([]string, error)
+}
+
+// SelectFrom lists the tags of repo with l and runs SelectErr on them.
+// ctx is passed to the lister; listing errors (including ctx cancellation)
+// are returned wrapped with the repo name.
+func SelectFrom(ctx context.Context, l TagLister, repo string, opt Options) ([]string, error) {
+	tags, err := l.ListTags(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("list tags of %s: %w", repo, err)
+	}
+
+	return SelectErr(tags, opt)
+}
@@ -0,0 +1,43 @@
+package rats
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeLister serves tags per repo and honors cancellation.
+type fakeLister map[string][]string
+
+func (f fakeLister) ListTags(ctx context.Context, repo string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tags, ok := f[repo]
+	if !ok {
+		return nil, errors.New("repository not found")
+	}
+
+	return tags, nil
+}
+
+func TestSelectFrom(t *testing.T) {
+	l := fakeLister{"library/app": {"1.0.0", "1.1.0", "v1.1.1", "latest"}}
+
+	got, err := SelectFrom(context.Background(), l, "library/app", DefaultOptions())
+	if err != nil {
+		t.Fatalf("SelectFrom: %v", err)
+	}
+	eqStrings(t, got, []string{"v1.1.1", "1.0.0"})
+
+	if _, err := SelectFrom(context.Background(), l, "missing", DefaultOptions()); err == nil {
+		t.Fatalf("want listing error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := SelectFrom(ctx, l, "library/app", DefaultOptions()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err=%v, want context.Canceled", err)
+	}
+}
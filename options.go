@@ -1,6 +1,13 @@
 package rats
 
-import "regexp"
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"time"
+)
 
 // Options configures filtering and sorting behavior.
 type Options struct {
@@ -10,47 +17,303 @@ type Options struct {
 	// Exclude negative regex filters applied to the raw tag and drop tags that match.
 	Exclude *regexp.Regexp
 
+	// IncludeAny keeps only tags matching at least one regex. ANDed with Include.
+	// Empty disables; nil entries never match.
+	IncludeAny []*regexp.Regexp
+
+	// ExcludeAny drops tags matching any regex (e.g. "-alpine$", "-slim$", "^nightly").
+	// Nil entries never match.
+	ExcludeAny []*regexp.Regexp
+
+	// MatchCanonical applies Include/IncludeAny/Exclude/ExcludeAny to the
+	// canonical form of SemVer tags (vMAJOR.MINOR.PATCH[-PRERELEASE], so
+	// "1.2", "v1.2.0" and "1.2.0+b1" all read "v1.2.0") instead of the raw
+	// tag; non-semver tags are still matched raw. The regexes then run after
+	// parsing (and its fallbacks) and before SemVer gating and Range, so every
+	// tag passing the other raw gates is parsed and canonicalized first.
+	// Globs, affixes and Ignore keep matching the raw tag.
+	MatchCanonical bool
+
+	// IncludeSuffixes keeps only tags ending with one of the suffixes.
+	// Plain string checks, evaluated before the regex and glob gates. Empty disables.
+	IncludeSuffixes []string
+
+	// ExcludeSuffixes drops tags ending with any suffix (e.g. "-alpine", "-slim").
+	ExcludeSuffixes []string
+
+	// ExcludePrefixes drops tags starting with any prefix (e.g. "nightly-").
+	ExcludePrefixes []string
+
+	// AffixIgnoreCase makes the suffix/prefix filters above case-insensitive.
+	AffixIgnoreCase bool
+
+	// CaseInsensitive matches Include/IncludeAny/Exclude/ExcludeAny, the globs
+	// and the signature check against the lowercased tag ("V1.2.3-RC1" reads
+	// "v1.2.3-rc1"), so patterns should be written in lowercase. It affects
+	// matching only: the output keeps the original case. FloatingTags and the
+	// 'v' of VPrefix are case-insensitive anyway.
+	CaseInsensitive bool
+
+	// IncludeGlob keeps only tags matching at least one shell glob ('*', '?',
+	// '[...]', '\\' escapes; anchored to the whole tag). ANDed with Include.
+	// Empty disables. Invalid patterns never match; SelectErr reports them.
+	IncludeGlob []string
+
+	// ExcludeGlob drops tags matching any shell glob. ANDed with Exclude.
+	// Empty disables. Invalid patterns never match; SelectErr reports them.
+	ExcludeGlob []string
+
+	// Ignore drops tags matched by .ratsignore-style rules (see ParseIgnore).
+	// Applied to the raw tag after Include/Exclude. Nil disables.
+	Ignore *IgnoreList
+
 	// Range clipping. Applied after parsing and before aggregation.
 	Range Range
 
+	// Ranges keeps versions inside at least one enabled range (OR), together
+	// with Range which acts as an extra first entry. Overlaps do not duplicate.
+	Ranges []Range
+
+	// Constraint is an npm/Composer-style expression ("^1.2", "~1.2.3",
+	// ">=1.2 <2 || >=3", see ParseConstraint) applied in place of Range.
+	// Empty disables. Mutually exclusive with Range/Ranges: SelectErr rejects both,
+	// Select applies both. Select ignores an invalid Constraint, like an
+	// invalid Range bound; SelectErr and Validate report it.
+	Constraint string
+
 	// Limit trims the output to at most N entries. 0 or negative means "no limit".
 	Limit int
 
+	// Offset skips the first N results before Limit is applied (pagination).
+	// Negative means 0; an Offset past the end yields an empty result.
+	Offset int
+
+	// LimitFromEnd makes Limit keep the last N results (after Offset)
+	// instead of the first N, e.g. the oldest N with SortDesc.
+	// Ignored with LimitSpreadMajors.
+	LimitFromEnd bool
+
+	// LimitSpreadMajors makes Limit reserve a slot for the first entry of every
+	// major (in output order) before filling the remaining slots in output order,
+	// so a small Limit does not hide older majors. The output order is kept.
+	LimitSpreadMajors bool
+
 	// Depth controls aggregation (patch/minor/major/latest).
 	Depth Depth
 
+	// KeepPerGroup keeps the N highest versions of every DepthMinor/DepthMajor
+	// bucket instead of only the best one; with DepthLatest it keeps the N
+	// latest overall. Members of a bucket are ordered best first before Sort.
+	// 0 or 1 means one per bucket; no-op for other depths.
+	KeepPerGroup int
+
+	// FallbackPrerelease makes DepthMinor/DepthMajor/DepthLatest pick the
+	// latest release of a bucket and fall back to its latest prerelease only
+	// when the bucket has no release. Without it the highest version wins, a
+	// prerelease included. With these depths it also lets prereleases through
+	// the Format release gate, so a prerelease-only bucket is kept.
+	FallbackPrerelease bool
+
+	// SameMajor restricts BestUpgrade to the major of the current version.
+	SameMajor bool
+
 	// FilterSemver enables SemVer gating (X.Y.Z[...]).
 	FilterSemver bool
 
+	// NonSemverPlacement puts tags that are not SemVer (kept without
+	// FilterSemver) after the SemVer ones (default), before them, or drops
+	// them. Each part keeps its own Sort order; Limit counts the joined list.
+	NonSemverPlacement NonSemverPlacement
+
+	// FloatingTags are non-semver tags ("latest", "stable", "edge") kept even
+	// under FilterSemver and release gating (Format) when a tag equals one of
+	// them, case-insensitively. They still pass the raw gates (Include,
+	// Exclude, Ignore, ...) and are placed per NonSemverPlacement, after the
+	// SemVer tags with NonSemverDrop.
+	FloatingTags []string
+
 	// Deduplicate merges aliases of the same semantic version
 	// (MAJOR.MINOR.PATCH + PRERELEASE; build is ignored) after parsing
 	// and before Depth* aggregation. Preserves the order of first appearance.
 	Deduplicate bool
 
+	// DedupPrefer selects which alias survives Deduplicate (default first
+	// seen). The survivor takes the position of the first alias.
+	DedupPrefer DedupPrefer
+
+	// DedupByMinor treats versions differing only in patch (and prerelease) as
+	// equal: each (major, minor) is kept once, as its latest version, at the
+	// position of its first appearance. Runs right after Deduplicate; a non-none
+	// Sort still reorders the result.
+	DedupByMinor bool
+
+	// DedupOutput drops repeated output strings, keeping the first, after
+	// rendering and before Offset/Limit. Distinct records can render alike,
+	// e.g. "1" and "1.0.0" both as "v1.0.0" with OutputCanonical and no
+	// Deduplicate. Applies to the string results (Select, SelectErr,
+	// SelectContext, Selector, SelectStream, SelectPage) and, by the rendered
+	// form without OutputTemplate, to SelectDetailed and SelectJSON.
+	DedupOutput bool
+
 	// OutputCanonical when true returns canonical version string (vMAJOR.MINOR.PATCH[-PRERELEASE]),
 	// build metadata stripped, otherwise returns the original input tag.
 	OutputCanonical bool
 
+	// OutputCanonicalWithBuild is OutputCanonical keeping build metadata:
+	// vMAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]. Implies OutputCanonical; affects
+	// rendering only. With Deduplicate the first seen alias survives, so its
+	// build is the one printed.
+	OutputCanonicalWithBuild bool
+
 	// OutputSemVer when true returns SemVer version string (MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]),
 	// otherwise returns the original input tag.
 	OutputSemVer bool
 
-	// ExcludeSignatures drops signature-like tags: sha256-<64 hex>.sig
+	// ReleaseQualifiers lists trailing 4th-component qualifiers (e.g. "RELEASE",
+	// "Final", "GA"; case-insensitive) that mark an otherwise invalid tag like
+	// "1.2.3.RELEASE" as the plain release 1.2.3. The raw tag is kept for output.
+	ReleaseQualifiers []string
+
+	// NormalizePrereleaseNumbers strips leading zeros from numeric prerelease
+	// identifiers ("1.2.3-rc.01" -> "rc.1") so such otherwise invalid tags
+	// parse and compare/dedup equal to their normalized form. Raw tag is kept for output.
+	NormalizePrereleaseNumbers bool
+
+	// EpochAware recognizes Debian-like "[E:]X.Y.Z[-R]" tags with an integer
+	// epoch E and/or revision R ("1:2.3.4-1") and orders versions by
+	// (epoch, SemVer, revision); absent parts are 0. A trailing integer after
+	// the last '-' is then always a revision ("1.2.3-9" is not a prerelease).
+	// Dedup keeps distinct epochs/revisions apart; Depth groups, Range and
+	// Constraint look at the SemVer core only. Other tags parse as usual.
+	EpochAware bool
+
+	// CalVer treats versions as calendar versions YYYY[.MM[.PATCH|.DD]]:
+	// zero-padded components ("2024.03.01") parse, and versions without a
+	// 4-digit year or with a month outside 1..12 ("2024.13.1", "1.2.3") are
+	// not versions (dropped with FilterSemver, kept as plain tags otherwise).
+	// Ordering stays SemVer, so DepthMajor is the latest per year, DepthMinor
+	// per month (ParseDepth accepts "year", "month" and "day"). Range bounds
+	// are written without padding ("2024.3").
+	CalVer bool
+
+	// RequireFullVersion drops shorthand X and X.Y versions, keeping only
+	// X.Y.Z[...], regardless of Format. Works with and without FilterSemver.
+	RequireFullVersion bool
+
+	// ExactComponents keeps only versions with exactly N numeric components
+	// (1 = X, 2 = X.Y, 3 = X.Y.Z), regardless of Format. 0 disables.
+	ExactComponents int
+
+	// OutputStripV trims a single leading 'v'/'V' followed by a digit from every
+	// rendered tag, semver or not ("v1.2" -> "1.2", "very" stays). Composes with
+	// OutputCanonical (MAJOR.MINOR.PATCH[-PRERELEASE]).
+	OutputStripV bool
+
+	// OutputAddV prepends 'v' to every rendered tag starting with a digit,
+	// semver or not ("1.2" -> "v1.2", "latest" stays); an existing 'v'/'V' is
+	// kept. Ignored when OutputStripV is set (strip wins).
+	OutputAddV bool
+
+	// OutputTemplate, when non-empty, renders every selected tag through a
+	// text/template instead of the output modes above. Fields: .Original,
+	// .Canonical, .Major, .Minor, .Patch, .Prerelease, .Build, .Semver
+	// (false for non-semver tags, which only have .Original).
+	// Example: "{{.Major}}.{{.Minor}} -> {{.Original}}". See SelectErr.
+	OutputTemplate string
+
+	// ExcludeSignatures drops signature-like tags: sha256-<64 hex> or
+	// sha512-<128 hex> followed by one of SignatureSuffixes.
 	ExcludeSignatures bool
 
+	// SignatureSuffixes lists exact suffixes after the digest for
+	// ExcludeSignatures (e.g. ".sig", ".att", ".sbom"). Empty means [".sig"].
+	SignatureSuffixes []string
+
+	// DropDigestLike drops commit/digest-like tags: pure lowercase hex of at
+	// least DigestLikeMinLen chars (e.g. "abc1234"). All-digit tags such as
+	// "1234567" are kept, they are valid X shorthand versions.
+	DropDigestLike bool
+
+	// DigestLikeMinLen is the minimal length for DropDigestLike. 0 means 7.
+	DigestLikeMinLen int
+
+	// Timestamps maps raw tags to their publish time, injected by the caller
+	// (registries are not queried). Used by MinAge.
+	Timestamps map[string]time.Time
+
+	// MinAge drops tags published less than MinAge before Now (bake time),
+	// based on Timestamps. Applied in the raw prefilter. 0 disables.
+	MinAge time.Duration
+
+	// Now is the reference time for MinAge. Zero means time.Now().
+	Now time.Time
+
+	// DropUndated drops tags missing from Timestamps when MinAge is set.
+	// By default they are kept.
+	DropUndated bool
+
 	// Format restricts allowed release format in mode (X/XY/XYZ).
 	// Default is FormatNone.
 	Format Format
 
+	// AllowBuildInRelease makes release gating (Format) keep versions with
+	// build metadata ("1.2.3+build.5") as long as they have no prerelease.
+	// By default gating drops them together with prereleases.
+	AllowBuildInRelease bool
+
+	// PrereleaseOnly keeps only versions with a prerelease component, the
+	// inverse of release gating. Implies FilterSemver. No-op when Format is
+	// set: release gating drops every prerelease and takes precedence.
+	PrereleaseOnly bool
+
+	// PrereleaseChannels, when non-empty, keeps only prereleases whose leading
+	// identifier (before the first '.') matches one of the channels,
+	// case-insensitively: ["rc"] keeps "1.2.0-rc.1", drops "1.2.0-beta.2" and
+	// every release. Implies FilterSemver. Applied after release gating (so it
+	// selects nothing together with Format) and before Range.
+	PrereleaseChannels []string
+
 	// Sort defines final output ordering (none/asc/desc).
 	Sort SortMode
 
+	// TieBreak orders tags with equal versions (aliases) when sorting.
+	TieBreak TieBreak
+
+	// CompareBuild orders SemVer-equal tags by build metadata before TieBreak
+	// in SortAsc/SortDesc (and WithinGroupSort): dot-separated identifiers,
+	// numeric ones numerically, so "1.2.3+build.10" follows "1.2.3+build.2".
+	// No build is lowest. Off by default, as SemVer ignores build in precedence.
+	CompareBuild bool
+
+	// WithinGroupSort orders members inside each group of SelectGrouped,
+	// independent of Sort (which orders the groups). SortNone keeps Sort order.
+	WithinGroupSort SortMode
+
 	// VPrefix controls whether tags must, may, or must not start with a leading 'v'.
 	// This only affects input acceptance. If OutputCanonical=true, the canonical
 	// string will use the "vMAJOR.MINOR.PATCH[...]" form per SemVer rules.
 	VPrefix VPrefix
+
+	// NormalizeAggregatedPrefix rewrites the leading 'v' of tags picked by Depth
+	// aggregation (minor/major/latest) or DedupByMinor: PrefixV adds it,
+	// PrefixNone strips it, PrefixAny keeps the winner's raw form.
+	NormalizeAggregatedPrefix VPrefix
+
+	// Parallelism parses inputs of at least 4096 tags with up to this many
+	// goroutines (semver parsing dominates large selections). 0 and 1 parse
+	// serially, a negative value uses GOMAXPROCS. Results are identical.
+	Parallelism int
+
+	// compiled IncludeGlob/ExcludeGlob/Constraint, set by normalized()
+	includeGlob []*regexp.Regexp
+	excludeGlob []*regexp.Regexp
+	constraint  *Constraint // compiled Constraint, nil when invalid
+	err         error
 }
 
+// defaultDigestLikeMinLen is the git short SHA length.
+const defaultDigestLikeMinLen = 7
+
 // normalized returns a copy with implicit defaults applied.
 func (o Options) normalized() Options {
 	out := o
@@ -59,14 +322,99 @@ func (o Options) normalized() Options {
 		out.Format = FormatNone
 	}
 
+	if o.MinAge > 0 && o.Now.IsZero() {
+		out.Now = time.Now()
+	}
+
+	var errs []error
+	out.includeGlob, out.excludeGlob, out.constraint = nil, nil, nil
+	if len(o.IncludeGlob) > 0 || len(o.ExcludeGlob) > 0 {
+		var incErr, excErr error
+		out.includeGlob, incErr = compileGlobs(o.IncludeGlob)
+		out.excludeGlob, excErr = compileGlobs(o.ExcludeGlob)
+		errs = append(errs, incErr, excErr)
+	}
+
+	if o.Constraint != "" {
+		c, err := ParseConstraint(o.Constraint)
+		out.constraint = c
+		errs = append(errs, err)
+
+		if len(out.ranges()) > 0 {
+			errs = append(errs, errors.New("constraint and range are mutually exclusive"))
+		}
+	}
+
+	for i, r := range out.ranges() {
+		errs = append(errs, r.validate(i))
+	}
+
+	out.err = errors.Join(errs...)
+
+	if o.DigestLikeMinLen <= 0 {
+		out.DigestLikeMinLen = defaultDigestLikeMinLen
+	}
+
 	// implies SemVer gating.
-	if (o.Format != FormatNone || o.OutputCanonical) && !o.FilterSemver {
+	if o.OutputCanonicalWithBuild {
+		out.OutputCanonical = true
+	}
+
+	if (o.Format != FormatNone || out.OutputCanonical || o.PrereleaseOnly || len(o.PrereleaseChannels) > 0 || o.NonSemverPlacement == NonSemverDrop) && !o.FilterSemver {
 		out.FilterSemver = true
 	}
 
 	return out
 }
 
+// Clone returns a copy of o whose slices and Timestamps map are not shared
+// with o, so concurrent callers can clone a base and tweak the copy, e.g.
+// append to ExcludeSuffixes, without racing. Compiled *regexp.Regexp values,
+// Ignore and the compiled Constraint are immutable and stay shared.
+func (o Options) Clone() Options {
+	c := o
+
+	c.IncludeAny = slices.Clone(o.IncludeAny)
+	c.ExcludeAny = slices.Clone(o.ExcludeAny)
+	c.IncludeSuffixes = slices.Clone(o.IncludeSuffixes)
+	c.ExcludeSuffixes = slices.Clone(o.ExcludeSuffixes)
+	c.ExcludePrefixes = slices.Clone(o.ExcludePrefixes)
+	c.IncludeGlob = slices.Clone(o.IncludeGlob)
+	c.ExcludeGlob = slices.Clone(o.ExcludeGlob)
+	c.Ranges = slices.Clone(o.Ranges)
+	c.ReleaseQualifiers = slices.Clone(o.ReleaseQualifiers)
+	c.SignatureSuffixes = slices.Clone(o.SignatureSuffixes)
+	c.PrereleaseChannels = slices.Clone(o.PrereleaseChannels)
+	c.FloatingTags = slices.Clone(o.FloatingTags)
+	c.Timestamps = maps.Clone(o.Timestamps)
+
+	c.includeGlob = slices.Clone(o.includeGlob)
+	c.excludeGlob = slices.Clone(o.excludeGlob)
+
+	return c
+}
+
+// compileGlobs compiles every pattern with compileGlob. Invalid patterns are
+// skipped (they never match) and reported in the joined error.
+func compileGlobs(ps []string) ([]*regexp.Regexp, error) {
+	if len(ps) == 0 {
+		return nil, nil
+	}
+
+	out := make([]*regexp.Regexp, 0, len(ps))
+	var errs []error
+	for _, p := range ps {
+		re, err := compileGlob(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("glob: %w", err))
+			continue
+		}
+		out = append(out, re)
+	}
+
+	return out, errors.Join(errs...)
+}
+
 // Depth controls aggregation granularity for SemVer-filtered tags.
 type Depth int
 
@@ -75,20 +423,32 @@ const (
 	// Select will not enforce SemVer gating via normalization.
 	// Use this when you want plain filtering/sorting without SemVer grouping.
 	DepthAny Depth = 0
+	// DepthNone is DepthAny under its CLI name: no grouping at all, the
+	// input order is kept (only an explicit Sort reorders). Unlike DepthPatch
+	// it does not imply the SemVer pipeline is in use.
+	DepthNone = DepthAny
 	// DepthPatch keeps all X.Y.Z* entries (no grouping),
 	// but works inside the SemVer pipeline (gating may be enabled).
 	DepthPatch = 1 << iota
-	// DepthMinor keeps the latest per (major, minor).
+	// DepthMinor keeps the latest per (major, minor). Like every grouping
+	// depth, with SortNone each group is emitted at the position of its
+	// first member in the input (first-seen order); only Sort reorders.
 	DepthMinor
 	// DepthMajor keeps the latest per major.
 	DepthMajor
 	// DepthLatest keeps a single latest tag overall.
 	DepthLatest
+	// DepthMajorChannels keeps up to two tags per major: the latest release
+	// and the latest prerelease when it is newer than that release.
+	// Prereleases only reach it when Format is FormatNone.
+	DepthMajorChannels
 )
 
 // String returns a stable textual representation for Depth.
 func (d Depth) String() string {
 	switch d {
+	case DepthMajorChannels:
+		return "major-channels"
 	case DepthLatest:
 		return "latest"
 	case DepthMajor:
@@ -106,34 +466,45 @@ func (d Depth) String() string {
 // Supported aliases (case-insensitive):
 //
 //	latest:  "latest","l","head","max","0"
-//	major:   "major","maj","x","1"
-//	minor:   "minor","min","xy","2"
-//	patch:   "patch","pth","xyz","3"
+//	major:   "major","maj","x","1","year"
+//	minor:   "minor","min","xy","2","month"
+//	patch:   "patch","pth","xyz","3","day"
+//	channels: "major-channels","channels","majch"
 //	any:     "any","none","off","raw","*"
 func ParseDepth(s string) Depth {
+	v, _ := parseDepth(s)
+	return v
+}
+
+// parseDepth is ParseDepth also reporting whether s is a known alias.
+func parseDepth(s string) (Depth, bool) {
 	switch toToken(s) {
 	// single latest
 	case "latest", "l", "head", "max", "0":
-		return DepthLatest
+		return DepthLatest, true
 
 	// aggregate per major X
-	case "major", "maj", "x", "1":
-		return DepthMajor
+	case "major", "maj", "x", "1", "year":
+		return DepthMajor, true
 
 	// aggregate per minor X.Y
-	case "minor", "min", "xy", "2":
-		return DepthMinor
+	case "minor", "min", "xy", "2", "month":
+		return DepthMinor, true
 
 	// keep all X.Y.Z
-	case "patch", "pth", "xyz", "3":
-		return DepthPatch
+	case "patch", "pth", "xyz", "3", "day":
+		return DepthPatch, true
+
+	// release + newer prerelease per major X
+	case "major-channels", "channels", "majch":
+		return DepthMajorChannels, true
 
 		// no semantic aggregation, do not force SemVer gating
-	case "any", "none", "off", "raw", "*":
-		return DepthAny
+	case "any", "none", "n", "off", "raw", "*":
+		return DepthAny, true
 
 	default:
-		return DepthAny
+		return DepthAny, false
 	}
 }
 
@@ -189,23 +560,30 @@ func (f Format) String() string {
 //	any:    "any", "all", "*", "x-xy-xyz"
 //	none:   "", "none", "no", "0", "n"
 func ParseFormat(s string) Format {
+	f, _ := parseFormat(s)
+	return f
+}
+
+// parseFormat is ParseFormat also reporting whether every token of s is known.
+func parseFormat(s string) (Format, bool) {
 	s = toToken(s)
 
 	// quick path for any/all
 	switch s {
 	case "", "none", "no", "0", "n":
-		return FormatNone
+		return FormatNone, true
 
 	case "any", "all", "*", "a":
-		return FormatAll
+		return FormatAll, true
 	}
 
 	toks := splitTokens(s)
 	if len(toks) == 0 {
-		return FormatXYZ
+		return FormatXYZ, false
 	}
 
 	var mask Format
+	known := true
 	for _, t := range toks {
 		switch t {
 		case "x", "1", "major", "maj":
@@ -214,14 +592,16 @@ func ParseFormat(s string) Format {
 			mask |= FormatXY
 		case "xyz", "3", "patch", "pth":
 			mask |= FormatXYZ
+		default:
+			known = false
 		}
 	}
 
 	if mask == 0 {
-		return FormatXYZ
+		return FormatXYZ, false
 	}
 
-	return mask
+	return mask, known
 }
 
 // SortMode controls the final output ordering.
@@ -234,6 +614,12 @@ const (
 	SortAsc = 1 << iota
 	// SortDesc sorts descending by SemVer (fallback to lexicographic).
 	SortDesc
+	// SortReverse reverses the order verbatim, without interpreting tags
+	// (input order for DepthNone/DepthPatch, first-seen order of groups otherwise).
+	SortReverse
+	// SortNatural sorts non-semver tags digit-aware ("img2" < "img10");
+	// SemVer tags are sorted ascending, which is their natural order.
+	SortNatural
 )
 
 // String returns a stable textual representation for SortMode.
@@ -243,6 +629,10 @@ func (m SortMode) String() string {
 		return "ascending"
 	case SortDesc:
 		return "descending"
+	case SortReverse:
+		return "reverse"
+	case SortNatural:
+		return "natural"
 	default:
 		return "none"
 	}
@@ -253,23 +643,39 @@ func (m SortMode) String() string {
 //
 //	asc:  "asc","ascending","inc","increase","up"
 //	desc: "desc","descending","dec","decrease","down"
+//	reverse: "reverse","rev","reversed"
+//	natural: "natural","nat","human"
 //	none: "none","default","asis"
 func ParseSort(s string) SortMode {
+	v, _ := parseSort(s)
+	return v
+}
+
+// parseSort is ParseSort also reporting whether s is a known alias.
+func parseSort(s string) (SortMode, bool) {
 	switch toToken(s) {
 	// ascending (low -> high)
 	case "asc", "ascending", "inc", "increase", "up":
-		return SortAsc
+		return SortAsc, true
 
 	// descending (high -> low)
 	case "desc", "descending", "dec", "decrease", "down":
-		return SortDesc
+		return SortDesc, true
+
+	// input order reversed
+	case "reverse", "rev", "reversed":
+		return SortReverse, true
+
+	// digit-aware lexical
+	case "natural", "nat", "human":
+		return SortNatural, true
 
 	// as is
 	case "none", "default", "asis":
-		return SortNone
+		return SortNone, true
 
 	default:
-		return SortNone
+		return SortNone, false
 	}
 }
 
@@ -310,31 +716,341 @@ func (m VPrefix) String() string {
 //	v:    "v", "with-v", "require-v", "required":
 //	none: "none", "no-v", "without-v", "forbidden":
 func ParseVPrefix(s string) VPrefix {
+	v, _ := parseVPrefix(s)
+	return v
+}
+
+// parseVPrefix is ParseVPrefix also reporting whether s is a known alias.
+func parseVPrefix(s string) (VPrefix, bool) {
 	switch toToken(s) {
 	case "", "any", "*", "auto":
-		return PrefixAny
+		return PrefixAny, true
 	case "v", "with-v", "require-v", "required":
-		return PrefixV
+		return PrefixV, true
 	case "none", "no-v", "without-v", "forbidden":
-		return PrefixNone
+		return PrefixNone, true
+	default:
+		return PrefixAny, false
+	}
+}
+
+// TieBreak orders SemVer-equal tags ("v1.2.3", "1.2.3", "1.2.3+b1") in SortAsc/SortDesc.
+type TieBreak uint8
+
+const (
+	// TieLexical compares raw tags lexically following the sort direction
+	// (ascending for SortAsc, descending for SortDesc), then input order.
+	TieLexical TieBreak = iota
+	// TieShortest puts shorter raw tags first in both directions, then
+	// falls back to TieLexical.
+	TieShortest
+	// TieInputOrder keeps input order in both directions.
+	TieInputOrder
+
+	// tieBuild is set on top of the TieBreak passed to the sort helpers when
+	// Options.CompareBuild orders equal versions by build first.
+	tieBuild TieBreak = 1 << 7
+)
+
+// sortTie returns the TieBreak for the sort helpers, tieBuild included.
+func (o Options) sortTie() TieBreak {
+	if o.CompareBuild {
+		return o.TieBreak | tieBuild
+	}
+
+	return o.TieBreak
+}
+
+// String returns a stable textual representation for TieBreak.
+func (t TieBreak) String() string {
+	switch t {
+	case TieShortest:
+		return "shortest"
+	case TieInputOrder:
+		return "input"
+	default:
+		return "lexical"
+	}
+}
+
+// ParseTieBreak maps free-form strings to TieBreak.
+// Supported aliases (case-insensitive):
+//
+//	lexical:  "", "lexical", "lex"
+//	shortest: "shortest", "short", "length"
+//	input:    "input", "input-order", "order", "stable"
+func ParseTieBreak(s string) TieBreak {
+	v, _ := parseTieBreak(s)
+	return v
+}
+
+// parseTieBreak is ParseTieBreak also reporting whether s is a known alias.
+func parseTieBreak(s string) (TieBreak, bool) {
+	switch toToken(s) {
+	case "shortest", "short", "length":
+		return TieShortest, true
+	case "input", "input-order", "order", "stable":
+		return TieInputOrder, true
+	case "", "lexical", "lex":
+		return TieLexical, true
+	default:
+		return TieLexical, false
+	}
+}
+
+// DedupPrefer selects which alias of a version survives Deduplicate.
+type DedupPrefer uint8
+
+const (
+	// PreferFirstSeen keeps the first alias in input order.
+	PreferFirstSeen DedupPrefer = iota
+	// PreferCanonical keeps the alias closest to "vMAJOR.MINOR.PATCH[-PRERELEASE]":
+	// an exact canonical spelling first, then full X.Y.Z without build, then
+	// full X.Y.Z, then any. Ties keep the first seen.
+	PreferCanonical
+	// PreferHighestBuild keeps the alias with the highest build metadata,
+	// compared per identifier like a prerelease (numeric ones numerically and
+	// below alphanumeric ones, lexical otherwise, more identifiers win on a
+	// common prefix). No build is lowest. Ties keep the first seen.
+	PreferHighestBuild
+	// PreferShortest keeps the shortest raw tag. Ties keep the first seen.
+	PreferShortest
+)
+
+// String returns a stable textual representation for DedupPrefer.
+func (p DedupPrefer) String() string {
+	switch p {
+	case PreferCanonical:
+		return "canonical"
+	case PreferHighestBuild:
+		return "highest-build"
+	case PreferShortest:
+		return "shortest"
+	default:
+		return "first-seen"
+	}
+}
+
+// ParseDedupPrefer maps free-form strings to DedupPrefer.
+// Supported aliases (case-insensitive):
+//
+//	first-seen:    "", "first", "first-seen"
+//	canonical:     "canonical", "canon"
+//	highest-build: "highest-build", "build"
+//	shortest:      "shortest", "short"
+func ParseDedupPrefer(s string) DedupPrefer {
+	v, _ := parseDedupPrefer(s)
+	return v
+}
+
+// parseDedupPrefer is ParseDedupPrefer also reporting whether s is a known alias.
+func parseDedupPrefer(s string) (DedupPrefer, bool) {
+	switch toToken(s) {
+	case "canonical", "canon":
+		return PreferCanonical, true
+	case "highest-build", "build":
+		return PreferHighestBuild, true
+	case "shortest", "short":
+		return PreferShortest, true
+	case "", "first", "first-seen":
+		return PreferFirstSeen, true
+	default:
+		return PreferFirstSeen, false
+	}
+}
+
+// NonSemverPlacement selects where tags without a SemVer version go in the output.
+type NonSemverPlacement uint8
+
+const (
+	// NonSemverAppend puts non-semver tags after SemVer ones (default).
+	NonSemverAppend NonSemverPlacement = iota
+	// NonSemverPrepend puts non-semver tags before SemVer ones.
+	NonSemverPrepend
+	// NonSemverDrop drops non-semver tags, like FilterSemver.
+	NonSemverDrop
+)
+
+// String returns a stable textual representation for NonSemverPlacement.
+func (p NonSemverPlacement) String() string {
+	switch p {
+	case NonSemverPrepend:
+		return "prepend"
+	case NonSemverDrop:
+		return "drop"
+	default:
+		return "append"
+	}
+}
+
+// ParseNonSemverPlacement maps free-form strings to NonSemverPlacement.
+// Supported aliases (case-insensitive):
+//
+//	append:  "", "append", "after", "last"
+//	prepend: "prepend", "before", "first"
+//	drop:    "drop", "none"
+func ParseNonSemverPlacement(s string) NonSemverPlacement {
+	v, _ := parseNonSemverPlacement(s)
+	return v
+}
+
+// parseNonSemverPlacement is ParseNonSemverPlacement also reporting whether
+// s is a known alias.
+func parseNonSemverPlacement(s string) (NonSemverPlacement, bool) {
+	switch toToken(s) {
+	case "prepend", "before", "first":
+		return NonSemverPrepend, true
+	case "drop", "none":
+		return NonSemverDrop, true
+	case "", "append", "after", "last":
+		return NonSemverAppend, true
 	default:
-		return PrefixAny
+		return NonSemverAppend, false
 	}
 }
 
 // Range clips versions to [Min, Max] with optional exclusive ends.
 // Min/Max accept X, X.Y, X.Y.Z (with optional 'v') or full SemVer (may include -prerelease).
 type Range struct {
-	Min string // empty => no lower bound
-	Max string // empty => no upper bound
+	Min string `json:"min,omitempty"` // empty => no lower bound
+	Max string `json:"max,omitempty"` // empty => no upper bound
 
 	// When true => exclusive bound. Default false => inclusive.
-	MinExclusive bool
-	MaxExclusive bool
+	MinExclusive bool `json:"minExclusive,omitempty"`
+	MaxExclusive bool `json:"maxExclusive,omitempty"`
 
 	// When Min is shorthand (X or X.Y), include pre-releases at the floor by using "-0".
 	// E.g. Min="1.2" + IncludePrerelease=true => lower floor is "1.2.0-0".
-	IncludePrerelease bool
+	IncludePrerelease bool `json:"includePrerelease,omitempty"`
+
+	// ExcludePrereleaseAtMax drops prereleases of a release ceiling: with
+	// Max="1.2.3" "1.2.3-rc.1" is dropped, mirroring the Min floor. By default
+	// they are kept as plain SemVer precedence puts them below the ceiling.
+	// See compileRange for all four combinations with MaxExclusive.
+	ExcludePrereleaseAtMax bool `json:"excludePrereleaseAtMax,omitempty"`
+}
+
+// Validate reports misconfiguration that Select silently tolerates: invalid
+// globs, Constraint or Range bounds (what SelectErr rejects), plus
+// contradictory settings Select resolves quietly or that select nothing,
+// such as OutputCanonical with OutputSemVer, PrereleaseOnly with Format or a
+// Min above Max. Select and SelectErr accept the latter for compatibility;
+// Validate is the recommended pre-check for user-supplied options.
+func (o Options) Validate() error {
+	n := o.normalized()
+	return errors.Join(n.err, n.conflicts())
+}
+
+// conflicts reports contradictory settings of normalized options.
+func (o Options) conflicts() error {
+	var errs []error
+	conflict := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if o.OutputCanonical && o.OutputSemVer {
+		conflict("OutputCanonical and OutputSemVer are mutually exclusive")
+	}
+	if o.OutputStripV && o.OutputAddV {
+		conflict("OutputStripV and OutputAddV are mutually exclusive")
+	}
+	if o.LimitFromEnd && o.LimitSpreadMajors {
+		conflict("LimitFromEnd is ignored with LimitSpreadMajors")
+	}
+
+	// release gating drops every prerelease
+	if o.Format != FormatNone {
+		if o.PrereleaseOnly {
+			conflict("PrereleaseOnly selects nothing with Format %s", o.Format)
+		}
+		if len(o.PrereleaseChannels) > 0 {
+			conflict("PrereleaseChannels select nothing with Format %s", o.Format)
+		}
+	}
+
+	switch n := o.ExactComponents; {
+	case n < 0 || n > 3:
+		conflict("ExactComponents must be 0..3, got %d", n)
+	case n > 0 && n < 3 && o.RequireFullVersion:
+		conflict("ExactComponents %d selects nothing with RequireFullVersion", n)
+	case n > 0 && o.Format != FormatNone && o.Format&formatOf(n) == 0:
+		conflict("ExactComponents %d selects nothing with Format %s", n, o.Format)
+	}
+	if o.RequireFullVersion && o.Format != FormatNone && o.Format&FormatXYZ == 0 {
+		conflict("RequireFullVersion selects nothing with Format %s", o.Format)
+	}
+
+	for i, r := range o.ranges() {
+		if r.empty() {
+			conflict("range %d: [%q, %q] is empty", i, r.Min, r.Max)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// formatOf returns the Format allowing n numeric components (1..3).
+func formatOf(n int) Format {
+	switch n {
+	case 1:
+		return FormatX
+	case 2:
+		return FormatXY
+	default:
+		return FormatXYZ
+	}
+}
+
+// fallbackPre reports whether FallbackPrerelease applies: a Depth grouping
+// by minor, by major or overall.
+func (o Options) fallbackPre() bool {
+	return o.FallbackPrerelease && (o.Depth == DepthMinor || o.Depth == DepthMajor || o.Depth == DepthLatest)
+}
+
+// ranges returns Range followed by Ranges, enabled ones only.
+func (o Options) ranges() []Range {
+	if !o.Range.Enabled() && len(o.Ranges) == 0 {
+		return nil
+	}
+
+	out := make([]Range, 0, 1+len(o.Ranges))
+	for _, r := range append([]Range{o.Range}, o.Ranges...) {
+		if r.Enabled() {
+			out = append(out, r)
+		}
+	}
+
+	return out
+}
+
+// validate reports non-empty bounds that do not parse. i is the position
+// in Options.ranges(), used in the message.
+func (r Range) validate(i int) error {
+	var errs []error
+	if r.Min != "" {
+		if _, ok := parseBound(r.Min, false, false); !ok {
+			errs = append(errs, fmt.Errorf("range %d: invalid min %q", i, r.Min))
+		}
+	}
+
+	if r.Max != "" {
+		if _, ok := parseBound(r.Max, false, true); !ok {
+			errs = append(errs, fmt.Errorf("range %d: invalid max %q", i, r.Max))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// empty reports whether both bounds parse and no version fits between them.
+func (r Range) empty() bool {
+	b := compileRange(r)
+	if !b.hasMin || !b.hasMax {
+		return false
+	}
+
+	c := b.minV.Compare(b.maxV)
+	return c > 0 || (c == 0 && (b.minEx || b.maxEx))
 }
 
 // Enabled if min or max bounds exists
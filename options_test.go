@@ -2,6 +2,7 @@ package rats
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -9,31 +10,38 @@ func TestParseDepth(t *testing.T) {
 	t.Parallel()
 
 	cases := map[string]Depth{
-		"":        DepthAny, // default
-		"any":     DepthAny,
-		"none":    DepthAny,
-		"off":     DepthAny,
-		"raw":     DepthAny,
-		"*":       DepthAny,
-		"latest":  DepthLatest,
-		"l":       DepthLatest,
-		"head":    DepthLatest,
-		"max":     DepthLatest,
-		"0":       DepthLatest,
-		"major":   DepthMajor,
-		"maj":     DepthMajor,
-		"x":       DepthMajor,
-		"1":       DepthMajor,
-		"minor":   DepthMinor,
-		"min":     DepthMinor,
-		"xy":      DepthMinor,
-		"2":       DepthMinor,
-		"patch":   DepthPatch,
-		"pth":     DepthPatch,
-		"xyz":     DepthPatch,
-		"3":       DepthPatch,
-		"unknown": DepthAny,   // fallback
-		"  MiN  ": DepthMinor, // case/space-insensitive
+		"":               DepthAny, // default
+		"any":            DepthAny,
+		"none":           DepthAny,
+		"n":              DepthNone,
+		"off":            DepthAny,
+		"raw":            DepthAny,
+		"*":              DepthAny,
+		"latest":         DepthLatest,
+		"l":              DepthLatest,
+		"head":           DepthLatest,
+		"max":            DepthLatest,
+		"0":              DepthLatest,
+		"major":          DepthMajor,
+		"maj":            DepthMajor,
+		"x":              DepthMajor,
+		"1":              DepthMajor,
+		"minor":          DepthMinor,
+		"min":            DepthMinor,
+		"xy":             DepthMinor,
+		"2":              DepthMinor,
+		"patch":          DepthPatch,
+		"pth":            DepthPatch,
+		"xyz":            DepthPatch,
+		"3":              DepthPatch,
+		"channels":       DepthMajorChannels,
+		"majch":          DepthMajorChannels,
+		"major-channels": DepthMajorChannels,
+		"year":           DepthMajor,
+		"month":          DepthMinor,
+		"day":            DepthPatch,
+		"unknown":        DepthAny,   // fallback
+		"  MiN  ":        DepthMinor, // case/space-insensitive
 	}
 
 	for in, want := range cases {
@@ -47,11 +55,12 @@ func TestDepthString(t *testing.T) {
 	t.Parallel()
 
 	cases := map[Depth]string{
-		DepthAny:    "any",
-		DepthPatch:  "patch",
-		DepthMinor:  "minor",
-		DepthMajor:  "major",
-		DepthLatest: "latest",
+		DepthAny:           "any",
+		DepthPatch:         "patch",
+		DepthMinor:         "minor",
+		DepthMajor:         "major",
+		DepthLatest:        "latest",
+		DepthMajorChannels: "major-channels",
 	}
 
 	for d, want := range cases {
@@ -157,6 +166,10 @@ func TestParseSort(t *testing.T) {
 		"none":       SortNone,
 		"default":    SortNone,
 		"asis":       SortNone,
+		"reverse":    SortReverse,
+		"rev":        SortReverse,
+		"natural":    SortNatural,
+		"human":      SortNatural,
 		"unknown":    SortNone,
 		"  DeSc  ":   SortDesc, // case/space-insensitive
 	}
@@ -242,3 +255,116 @@ func TestOptionsZeroValue(t *testing.T) {
 		t.Fatalf("zero Options = %#v; want %#v", opt, want)
 	}
 }
+
+func TestOptionsValidate_RangeBounds(t *testing.T) {
+	in := []string{"1.0.0", "2.0.0"}
+
+	opt := Options{Range: Range{Min: "1.x.y", Max: "2"}}
+	err := opt.Validate()
+	if err == nil || !strings.Contains(err.Error(), `"1.x.y"`) {
+		t.Fatalf("Validate()=%v, want error naming the bound", err)
+	}
+
+	if _, err := SelectErr(in, opt); err == nil {
+		t.Fatalf("SelectErr: want error")
+	}
+
+	// Select stays tolerant: the bad bound is ignored
+	eqStrings(t, Select(in, opt), in)
+
+	opt = Options{Ranges: []Range{{Min: "1"}, {Max: "nope"}}}
+	if err := opt.Validate(); err == nil || !strings.Contains(err.Error(), `"nope"`) {
+		t.Fatalf("Validate()=%v, want max error", err)
+	}
+
+	if err := (Options{Range: Range{Min: "1.2", Max: "v2.0.0-rc.1"}}).Validate(); err != nil {
+		t.Fatalf("Validate()=%v, want nil", err)
+	}
+}
+
+func TestOptionsValidate_Conflicts(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		opt  Options
+		want string
+	}{
+		{Options{OutputCanonical: true, OutputSemVer: true}, "OutputCanonical and OutputSemVer"},
+		{Options{OutputCanonicalWithBuild: true, OutputSemVer: true}, "OutputCanonical and OutputSemVer"},
+		{Options{OutputStripV: true, OutputAddV: true}, "OutputStripV and OutputAddV"},
+		{Options{Limit: 2, LimitFromEnd: true, LimitSpreadMajors: true}, "LimitFromEnd"},
+		{Options{PrereleaseOnly: true, Format: FormatXYZ}, "PrereleaseOnly selects nothing with Format xyz"},
+		{Options{PrereleaseChannels: []string{"rc"}, Format: FormatAll}, "PrereleaseChannels"},
+		{Options{ExactComponents: 4}, "must be 0..3"},
+		{Options{ExactComponents: 2, RequireFullVersion: true}, "RequireFullVersion"},
+		{Options{ExactComponents: 1, Format: FormatXY | FormatXYZ}, "ExactComponents 1 selects nothing"},
+		{Options{RequireFullVersion: true, Format: FormatX}, "RequireFullVersion selects nothing"},
+		{Options{Range: Range{Min: "2", Max: "1.9"}}, "range 0"},
+		{Options{Ranges: []Range{{Min: "1"}, {Min: "1.2.3", Max: "1.2.3", MaxExclusive: true}}}, "range 1"},
+	}
+	for _, c := range cases {
+		err := c.opt.Validate()
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%+v: Validate()=%v, want %q", c.opt.Config(), err, c.want)
+		}
+
+		// SelectErr keeps accepting them
+		if _, err := SelectErr([]string{"1.2.3"}, c.opt); err != nil {
+			t.Errorf("%+v: SelectErr: %v", c.opt.Config(), err)
+		}
+	}
+
+	for _, opt := range []Options{
+		DefaultOptions(),
+		{PrereleaseOnly: true},
+		{ExactComponents: 2, Format: FormatXY},
+		{Range: Range{Min: "1.2.3", Max: "1.2.3"}},
+		{Range: Range{Min: "1.2", Max: "1.2.0", IncludePrerelease: true, MaxExclusive: true}},
+	} {
+		if err := opt.Validate(); err != nil {
+			t.Errorf("%+v: Validate()=%v, want nil", opt.Config(), err)
+		}
+	}
+}
+
+func TestOptionsClone(t *testing.T) {
+	t.Parallel()
+
+	// fill every exported slice and map field so new ones are covered too
+	var opt Options
+	v := reflect.ValueOf(&opt).Elem()
+	for i := range v.NumField() {
+		f := v.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.Slice:
+			f.Set(reflect.MakeSlice(f.Type(), 1, 1))
+		case reflect.Map:
+			m := reflect.MakeMap(f.Type())
+			m.SetMapIndex(reflect.New(f.Type().Key()).Elem(), reflect.New(f.Type().Elem()).Elem())
+			f.Set(m)
+		}
+	}
+
+	c := reflect.ValueOf(opt.Clone())
+	if !reflect.DeepEqual(c.Interface(), opt) {
+		t.Fatalf("clone differs from original")
+	}
+
+	for i := range v.NumField() {
+		f := v.Field(i)
+		if !f.CanSet() || (f.Kind() != reflect.Slice && f.Kind() != reflect.Map) {
+			continue
+		}
+		if f.Pointer() == c.Field(i).Pointer() {
+			t.Errorf("%s is shared with the clone", v.Type().Field(i).Name)
+		}
+	}
+
+	var zero Options
+	if got := zero.Clone(); !reflect.DeepEqual(got, zero) {
+		t.Fatalf("zero clone = %#v", got)
+	}
+}
@@ -0,0 +1,257 @@
+package rats
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/woozymasta/semver"
+)
+
+// VersionInfo is a structured description of a selected tag.
+// Non-semver tags only carry Raw and Rendered (and Semver=false in JSON).
+type VersionInfo struct {
+	// Raw is the original input tag.
+	Raw string `json:"raw"`
+
+	// Rendered is the tag as Select outputs it (OutputCanonical, OutputSemVer,
+	// OutputStripV, ... applied; OutputTemplate is not).
+	Rendered string `json:"rendered"`
+
+	// Canonical is "vMAJOR.MINOR.PATCH[-PRERELEASE]".
+	Canonical string `json:"canonical"`
+
+	// Prerelease without the leading '-'.
+	Prerelease string `json:"prerelease"`
+
+	// Build metadata without the leading '+'.
+	Build string `json:"build"`
+
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+
+	// Semver reports whether Raw parsed as SemVer.
+	Semver bool `json:"semver"`
+
+	// IsRelease reports a version without prerelease and build.
+	IsRelease bool `json:"isRelease"`
+}
+
+// MarshalJSON emits only raw, rendered and "semver":false for non-semver tags.
+func (vi VersionInfo) MarshalJSON() ([]byte, error) {
+	if !vi.Semver {
+		return json.Marshal(struct {
+			Raw      string `json:"raw"`
+			Rendered string `json:"rendered"`
+			Semver   bool   `json:"semver"`
+		}{Raw: vi.Raw, Rendered: vi.Rendered})
+	}
+
+	type plain VersionInfo
+	return json.Marshal(plain(vi))
+}
+
+// SelectDetailed runs the Select pipeline (same Depth/Sort/Limit) and
+// returns structured metadata for every selected tag in output order.
+// DedupOutput drops records rendering like an earlier one (OutputTemplate
+// aside), as in Select.
+func SelectDetailed(in []string, opt Options) []VersionInfo {
+	opt = opt.normalized()
+
+	var rs []rec
+	if opt.DedupOutput {
+		rs = limitRecs(uniqueRendered(selectRecs(in, opt), opt), opt)
+	} else {
+		rs = selectLimited(in, opt)
+	}
+
+	out := make([]VersionInfo, 0, len(rs))
+	for i := range rs {
+		out = append(out, versionInfo(&rs[i], opt))
+	}
+
+	return out
+}
+
+// SelectJSON is SelectDetailed encoded as a JSON array of objects:
+//
+//	{"raw":"v1.2.3-rc.1","rendered":"v1.2.3-rc.1","canonical":"v1.2.3-rc.1",
+//	 "prerelease":"rc.1","build":"","major":1,"minor":2,"patch":3,
+//	 "semver":true,"isRelease":false}
+//	{"raw":"latest","rendered":"latest","semver":false}
+func SelectJSON(in []string, opt Options) ([]byte, error) {
+	return json.Marshal(SelectDetailed(in, opt))
+}
+
+// uniqueRendered filters rs in place to the first record of every rendered
+// string (renderRec).
+func uniqueRendered(rs []rec, opt Options) []rec {
+	seen := make(map[string]struct{}, len(rs))
+	out := rs[:0]
+	for i := range rs {
+		s := renderRec(&rs[i], opt)
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		out = append(out, rs[i])
+	}
+
+	return out
+}
+
+// versionInfo describes a record rendered per opt.
+func versionInfo(r *rec, opt Options) VersionInfo {
+	if !r.ver.Valid {
+		return VersionInfo{Raw: r.raw, Rendered: r.raw}
+	}
+
+	v := &r.ver
+	return VersionInfo{
+		Raw:        r.raw,
+		Rendered:   renderRec(r, opt),
+		Canonical:  v.Canonical(),
+		Prerelease: v.Prerelease,
+		Build:      v.Build,
+		Major:      v.Major,
+		Minor:      v.Minor,
+		Patch:      v.Patch,
+		Semver:     true,
+		IsRelease:  !has(v.Flags, semver.FlagHasPre) && !has(v.Flags, semver.FlagHasBuild),
+	}
+}
+
+// TemplateData is the value passed to Options.OutputTemplate for each tag.
+type TemplateData struct {
+	// Original is the raw input tag.
+	Original string
+
+	// Canonical is "vMAJOR.MINOR.PATCH[-PRERELEASE]", empty for non-semver.
+	Canonical string
+
+	// Prerelease without the leading '-'.
+	Prerelease string
+
+	// Build metadata without the leading '+'.
+	Build string
+
+	Major int
+	Minor int
+	Patch int
+
+	// Semver reports whether Original parsed as SemVer.
+	Semver bool
+}
+
+// compileOutputTemplate parses text; empty text yields a nil template.
+func compileOutputTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	t, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("output template: %w", err)
+	}
+
+	return t, nil
+}
+
+// renderTemplate executes t for every record.
+func renderTemplate(rs []rec, t *template.Template) ([]string, error) {
+	out := make([]string, 0, len(rs))
+
+	var b strings.Builder
+	for i := range rs {
+		r := &rs[i]
+		d := TemplateData{Original: r.raw}
+		if r.ver.Valid {
+			d.Canonical = r.ver.Canonical()
+			d.Prerelease = r.ver.Prerelease
+			d.Build = r.ver.Build
+			d.Major, d.Minor, d.Patch = r.ver.Major, r.ver.Minor, r.ver.Patch
+			d.Semver = true
+		}
+
+		b.Reset()
+		if err := t.Execute(&b, d); err != nil {
+			return nil, fmt.Errorf("output template: %w", err)
+		}
+		out = append(out, b.String())
+	}
+
+	return out, nil
+}
+
+// renderRecs renders records per output mode. Non-semver records keep their raw form.
+func renderRecs(rs []rec, opt Options) []string {
+	out := make([]string, 0, len(rs))
+	for i := range rs {
+		out = append(out, renderRec(&rs[i], opt))
+	}
+
+	return out
+}
+
+// renderRec renders a single record per output mode and prefix policy.
+func renderRec(r *rec, opt Options) string {
+	s := renderVersion(r, opt)
+	switch {
+	case opt.OutputStripV:
+		s = stripV(s)
+	case opt.OutputAddV:
+		s = addV(s)
+	}
+
+	return s
+}
+
+// renderVersion renders a single record per output mode.
+func renderVersion(r *rec, opt Options) string {
+	switch {
+	case !r.ver.Valid:
+		return r.raw
+	case opt.OutputCanonicalWithBuild:
+		return withEpochRev(r, r.ver.Print(semver.PrintMaskCanonical|semver.PrintBuild))
+	case opt.OutputCanonical:
+		return withEpochRev(r, r.ver.Canonical())
+	case opt.OutputSemVer:
+		return withEpochRev(r, r.ver.SemVer())
+	default:
+		return r.raw
+	}
+}
+
+// withEpochRev puts the EpochAware epoch and revision of r back around the
+// rendered version s ("1:v2.3.4-1").
+func withEpochRev(r *rec, s string) string {
+	if r.epoch > 0 {
+		s = strconv.Itoa(int(r.epoch)) + ":" + s
+	}
+	if r.rev > 0 {
+		s += "-" + strconv.Itoa(int(r.rev))
+	}
+
+	return s
+}
+
+// stripV removes a leading 'v'/'V' when a digit follows.
+func stripV(s string) string {
+	if len(s) > 1 && (s[0] == 'v' || s[0] == 'V') && s[1] >= '0' && s[1] <= '9' {
+		return s[1:]
+	}
+
+	return s
+}
+
+// addV prepends 'v' when s starts with a digit.
+func addV(s string) string {
+	if len(s) > 0 && s[0] >= '0' && s[0] <= '9' {
+		return "v" + s
+	}
+
+	return s
+}
@@ -0,0 +1,129 @@
+package rats
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSelectJSON(t *testing.T) {
+	in := []string{"v1.2.3-rc.1+b5", "0.1.0", "latest"}
+
+	data, err := SelectJSON(in, Options{Sort: SortDesc})
+	if err != nil {
+		t.Fatalf("SelectJSON: %v", err)
+	}
+
+	want := `[` +
+		`{"raw":"v1.2.3-rc.1+b5","rendered":"v1.2.3-rc.1+b5","canonical":"v1.2.3-rc.1","prerelease":"rc.1","build":"b5","major":1,"minor":2,"patch":3,"semver":true,"isRelease":false},` +
+		`{"raw":"0.1.0","rendered":"0.1.0","canonical":"v0.1.0","prerelease":"","build":"","major":0,"minor":1,"patch":0,"semver":true,"isRelease":true},` +
+		`{"raw":"latest","rendered":"latest","semver":false}` +
+		`]`
+	if string(data) != want {
+		t.Fatalf("got  %s\nwant %s", data, want)
+	}
+
+	var back []map[string]any
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(back) != 3 || back[2]["semver"] != false {
+		t.Fatalf("round trip: %v", back)
+	}
+}
+
+func TestSelectDetailed_Rendered(t *testing.T) {
+	in := []string{"1.2.3+b1", "latest"}
+
+	got := SelectDetailed(in, Options{})
+	if len(got) != 2 || got[0].Rendered != "1.2.3+b1" || got[1].Rendered != "latest" {
+		t.Fatalf("plain: %+v", got)
+	}
+
+	// canonical output implies FilterSemver
+	got = SelectDetailed(in, Options{OutputCanonical: true})
+	if len(got) != 1 || got[0].Rendered != "v1.2.3" {
+		t.Fatalf("canonical: %+v", got)
+	}
+}
+
+func TestSelectDetailed_FollowsPipeline(t *testing.T) {
+	in := []string{"1.0.0", "1.1.0", "1.1.1", "2.0.0"}
+
+	got := SelectDetailed(in, Options{Depth: DepthMinor, Sort: SortDesc, Limit: 2, FilterSemver: true})
+	if len(got) != 2 || got[0].Raw != "2.0.0" || got[1].Raw != "1.1.1" {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestSelect_OutputCanonicalWithBuild(t *testing.T) {
+	in := []string{"1.2.3+b9", "v1.2.3+b1", "1.3", "2.0.0-rc.1+sha.abc", "latest"}
+
+	got := Select(in, Options{OutputCanonicalWithBuild: true})
+	eqStrings(t, got, []string{"v1.2.3+b9", "v1.2.3+b1", "v1.3.0", "v2.0.0-rc.1+sha.abc"})
+
+	// dedup keeps the first seen alias and its build
+	got = Select(in, Options{OutputCanonicalWithBuild: true, Deduplicate: true})
+	eqStrings(t, got, []string{"v1.2.3+b9", "v1.3.0", "v2.0.0-rc.1+sha.abc"})
+
+	// plain canonical still strips build
+	got = Select(in, Options{OutputCanonical: true, Deduplicate: true})
+	eqStrings(t, got, []string{"v1.2.3", "v1.3.0", "v2.0.0-rc.1"})
+}
+
+func TestSelect_OutputStripV(t *testing.T) {
+	in := []string{"v1.2.3", "1.2.4", "V2", "very", "v", "v1.x"}
+
+	// string-only parts too, but only a 'v' before a digit
+	got := Select(in, Options{OutputStripV: true})
+	eqStrings(t, got, []string{"1.2.3", "1.2.4", "2", "very", "v", "1.x"})
+
+	got = Select(in, Options{OutputStripV: true, OutputCanonical: true})
+	eqStrings(t, got, []string{"1.2.3", "1.2.4", "2.0.0"})
+}
+
+func TestSelect_OutputAddV(t *testing.T) {
+	in := []string{"v1.2.3", "1.2.4", "V2", "1.2", "latest", "1.2.3.4"}
+
+	got := Select(in, Options{OutputAddV: true})
+	eqStrings(t, got, []string{"v1.2.3", "v1.2.4", "V2", "v1.2", "latest", "v1.2.3.4"})
+
+	got = Select(in, Options{OutputAddV: true, OutputSemVer: true, FilterSemver: true})
+	eqStrings(t, got, []string{"v1.2.3", "v1.2.4", "v2.0.0", "v1.2.0"})
+
+	// strip wins when both are set
+	got = Select(in, Options{OutputAddV: true, OutputStripV: true, FilterSemver: true})
+	eqStrings(t, got, []string{"1.2.3", "1.2.4", "2", "1.2"})
+}
+
+func TestSelectErr_OutputTemplate(t *testing.T) {
+	in := []string{"v1.2.3", "1.3.0-rc.1", "latest"}
+	opt := Options{
+		Sort:           SortAsc,
+		OutputTemplate: `{{if .Semver}}{{.Major}}.{{.Minor}} -> {{.Original}} ({{.Canonical}}){{else}}{{.Original}}{{end}}`,
+	}
+
+	got, err := SelectErr(in, opt)
+	if err != nil {
+		t.Fatalf("SelectErr: %v", err)
+	}
+	eqStrings(t, got, []string{"1.2 -> v1.2.3 (v1.2.3)", "1.3 -> 1.3.0-rc.1 (v1.3.0-rc.1)", "latest"})
+
+	// Limit still applies to templated output
+	opt.Limit = 1
+	got, _ = SelectErr(in, opt)
+	eqStrings(t, got, []string{"1.2 -> v1.2.3 (v1.2.3)"})
+}
+
+func TestSelectErr_BadTemplate(t *testing.T) {
+	in := []string{"1.0.0", "2.0.0"}
+
+	if _, err := SelectErr(in, Options{OutputTemplate: "{{.Major"}); err == nil {
+		t.Fatalf("want compile error")
+	}
+	if _, err := SelectErr(in, Options{OutputTemplate: "{{.Nope}}"}); err == nil {
+		t.Fatalf("want execution error")
+	}
+
+	// Select stays tolerant and falls back to plain rendering
+	eqStrings(t, Select(in, Options{OutputTemplate: "{{.Major"}), in)
+}
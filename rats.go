@@ -1,5 +1,13 @@
 package rats
 
+import (
+	"context"
+	"slices"
+	"text/template"
+
+	"github.com/woozymasta/semver"
+)
+
 // DefaultOptions returns a practical preset for stable releases:
 //
 //   - FilterSemver: true          // only SemVer-like tags
@@ -20,104 +28,479 @@ func DefaultOptions() Options {
 	}
 }
 
+// PrereleaseOptions returns options selecting every prerelease, newest first:
+//
+//   - FilterSemver:   true          // only SemVer-like tags
+//   - PrereleaseOnly: true          // drop releases
+//   - Sort:           SortDesc      // newest first
+//   - Deduplicate:    true          // collapse equivalent format
+func PrereleaseOptions() Options {
+	return Options{
+		FilterSemver:   true,
+		PrereleaseOnly: true,
+		Sort:           SortDesc,
+		Deduplicate:    true,
+	}
+}
+
+// AllVersionsOptions returns options selecting every SemVer version,
+// releases and prereleases alike, without release gating or aggregation:
+//
+//   - FilterSemver: true          // only SemVer-like tags
+//   - Sort:         SortDesc      // newest first
+//   - Deduplicate:  true          // collapse equivalent format
+//
+// Set Depth to DepthMajor for the latest version per major, prereleases included.
+func AllVersionsOptions() Options {
+	return Options{
+		FilterSemver: true,
+		Sort:         SortDesc,
+		Deduplicate:  true,
+	}
+}
+
 // Select filters, aggregates, and sorts tags.
 // Simple, readable pipeline:
 //  1. cheap raw prefilter (VPrefix/regex/signatures)
 //  2. parse all (once)
 //  3. if no semver at all -> string-only path (lex sort, limit)
 //  4. else -> semver path (Format -> Range -> Dedup -> Depth -> Sort)
-//     non-semver are kept only when not gating by semver, and appended after semver.
+//     non-semver are kept only when not gating by semver (FloatingTags aside),
+//     and placed after or before semver per NonSemverPlacement.
+//
+// Select is tolerant: invalid IncludeGlob/ExcludeGlob patterns and an invalid
+// Constraint never match, an unparseable Range bound is ignored, and an
+// invalid OutputTemplate falls back to the plain rendering.
+// Use SelectErr or Options.Validate to detect such misconfiguration.
+//
+// The result is never nil: no match yields an empty slice ("[]" in JSON).
 func Select(in []string, opt Options) []string {
 	opt = opt.normalized()
 
+	out, err := selectOut(in, opt)
+	if err != nil {
+		opt.OutputTemplate = ""
+		out, _ = selectOut(in, opt)
+	}
+
+	return out
+}
+
+// SelectErr is Select reporting configuration and rendering errors
+// (see Options.Validate, plus OutputTemplate compile or execution errors)
+// instead of ignoring them.
+func SelectErr(in []string, opt Options) ([]string, error) {
+	opt = opt.normalized()
+	if opt.err != nil {
+		return nil, opt.err
+	}
+
+	return selectOut(in, opt)
+}
+
+// SelectUnique is Select with DedupOutput set: the result has no repeated
+// strings, whatever the output mode.
+func SelectUnique(in []string, opt Options) []string {
+	opt.DedupOutput = true
+	return Select(in, opt)
+}
+
+// selectOut runs the full pipeline on normalized options and renders the output.
+func selectOut(in []string, opt Options) ([]string, error) {
+	return selectOutWith(in, opt, &workspace{})
+}
+
+// selectOutWith is selectOut using the buffers of ws.
+func selectOutWith(in []string, opt Options, ws *workspace) ([]string, error) {
+	tmpl, err := compileOutputTemplate(opt.OutputTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	rs := selectRecsWith(in, opt, ws, sortLimit(opt))
+	if ws.err != nil {
+		return nil, ws.err
+	}
+	if rs == nil {
+		return []string{}, nil // never nil: encodes as [] in JSON
+	}
+
+	if opt.DedupOutput {
+		return renderUnique(rs, opt, tmpl)
+	}
+
+	return render(limitRecs(rs, opt), opt, tmpl)
+}
+
+// render renders rs with tmpl, or per output mode when tmpl is nil.
+func render(rs []rec, opt Options, tmpl *template.Template) ([]string, error) {
+	if tmpl != nil {
+		return renderTemplate(rs, tmpl)
+	}
+
+	return renderRecs(rs, opt), nil
+}
+
+// renderUnique renders all of rs, drops repeated output strings keeping the
+// first, then applies Offset/Limit to what is left.
+func renderUnique(rs []rec, opt Options, tmpl *template.Template) ([]string, error) {
+	out, err := render(rs, opt, tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(out))
+	n := 0
+	for i, s := range out {
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+
+		rs[n] = rs[i]
+		rs[n].raw = s // rendered form rides along through limitRecs
+		n++
+	}
+
+	rs = limitRecs(rs[:n], opt)
+	out = out[:0]
+	for i := range rs {
+		out = append(out, rs[i].raw)
+	}
+
+	return out, nil
+}
+
+// SelectContext is SelectErr that stops with ctx.Err() once ctx is done.
+// Cancellation is checked every 16384 tags while prefiltering and parsing
+// and between the later stages (filters, aggregation, sort), so the worst
+// case latency is one such stage, e.g. sorting the filtered versions.
+func SelectContext(ctx context.Context, in []string, opt Options) ([]string, error) {
+	opt = opt.normalized()
+	if opt.err != nil {
+		return nil, opt.err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return selectOutWith(in, opt, &workspace{ctx: ctx})
+}
+
+// SelectParsed runs the same pipeline as Select and returns the selected
+// versions as parsed semver values (Original set) in output order.
+// Non-semver tags have no parsed form and are omitted; Limit is applied
+// to the full output before they are dropped.
+func SelectParsed(in []string, opt Options) []semver.Semver {
+	opt = opt.normalized()
+
+	rs := selectLimited(in, opt)
+	out := make([]semver.Semver, 0, len(rs))
+	for _, r := range rs {
+		if r.ver.Valid {
+			out = append(out, r.ver)
+		}
+	}
+
+	return out
+}
+
+// SelectVersions is an alias of SelectParsed: it returns the selected SemVer
+// tags as parsed semver.Semver values (Original preserved) in output order.
+// Non-semver tags are omitted since they have no parsed form.
+func SelectVersions(in []string, opt Options) []semver.Semver {
+	return SelectParsed(in, opt)
+}
+
+// SelectPage runs Select once and returns one page of the result together
+// with the total number of selected tags. Pages are 1-based; a page past the
+// end, page < 1 or size < 1 yield empty items with the correct total.
+func SelectPage(in []string, opt Options, page, size int) (items []string, total int) {
+	all := Select(in, opt)
+	total = len(all)
+
+	if page < 1 || size < 1 {
+		return []string{}, total
+	}
+
+	pages := total / size
+	if total%size != 0 {
+		pages++
+	}
+
+	if page > pages {
+		return []string{}, total
+	}
+
+	lo := (page - 1) * size
+	hi := min(lo+size, total)
+
+	return all[lo:hi:hi], total
+}
+
+// selectRecs runs steps 1-4 of the pipeline on normalized options and returns
+// the ordered records: semver and non-semver (invalid ver) in the order of
+// NonSemverPlacement, so callers wanting semver only must skip invalid ones.
+// Returns nil when nothing survives before parsing.
+func selectRecs(in []string, opt Options) []rec {
+	return selectRecsWith(in, opt, &workspace{}, 0)
+}
+
+// selectRecsWith is selectRecs using the buffers of ws. The result may
+// share them, so it is valid until the next use of ws.
+// A positive top (see sortLimit) lets sorting keep only the first top
+// records when the output is cut to them by limitRecs anyway.
+// With a ws context, returns nil once it is done (ws.err set).
+func selectRecsWith(in []string, opt Options, ws *workspace, top int) []rec {
 	// 1) raw prefilter
-	raw := preFilterRaw(in, opt)
+	raw := grab(ws.raw, len(in))
+	for lo, step := 0, ws.step(len(in)); lo < len(in); lo += step {
+		if ws.canceled() {
+			return nil
+		}
+		raw = preFilterRawInto(raw, in[lo:min(lo+step, len(in))], opt)
+	}
+	ws.raw = raw
 	if len(raw) == 0 {
 		return nil
 	}
 
 	// 2) parse once
-	rs, semCount := parseAll(raw)
+	rs := grab(ws.recs, len(raw))[:len(raw)]
+	ws.recs = rs
+	semCount := 0
+	for lo, step := 0, ws.step(len(raw)); lo < len(raw); lo += step {
+		if ws.canceled() {
+			return nil
+		}
+		hi := min(lo+step, len(raw))
+		semCount += parseAllInto(rs[lo:hi], raw[lo:hi], lo, opt.Parallelism)
+	}
+	semCount += parseFallbacks(rs, semCount, opt)
+
+	// regex gates on the canonical form, raw kept in step for the string-only path
+	if opt.MatchCanonical {
+		ws.trace.mark(rs)
+		rs, semCount = filterCanonical(rs, opt)
+		ws.trace.dropCanonical(rs, opt)
+		raw = raw[:0]
+		for i := range rs {
+			raw = append(raw, rs[i].raw)
+		}
+	}
+
+	if ws.canceled() {
+		return nil
+	}
 
 	// 3) if there are no semver at all -> string-only pipeline
 	if semCount == 0 {
 		if opt.FilterSemver {
-			return nil
+			ws.trace.dropNonSemver(rs, opt.FloatingTags)
+			if raw = keepFloating(raw, opt.FloatingTags); len(raw) == 0 {
+				return nil
+			}
 		}
 
-		out := stringOnlyPipeline(raw, opt)
-		return capStrings(out, opt.Limit)
+		ws.out = joinRecs(grab(ws.out, len(raw)), nil, stringOnlyPipeline(raw, opt))
+
+		return ws.out
 	}
 
 	// 4) semver pipeline
-	sem, other := splitSemver(rs)
+	sem, other := splitSemverInto(grab(ws.sem, semCount), grab(ws.other, len(rs)-semCount), rs)
+	ws.sem, ws.other = sem, other
 
 	// SemVer gating: ReleaseOnly / FilterSemver
+	if opt.FilterSemver {
+		ws.trace.dropNonSemver(rs, opt.FloatingTags)
+	}
 	if opt.Format != FormatNone {
-		sem = filterReleaseOnly(sem, opt.Format)
-		// non-semver are dropped in ReleaseOnly mode
-		other = nil
+		ws.trace.mark(sem)
+		// FallbackPrerelease: prereleases go on to aggregation, which drops the shadowed ones
+		sem = filterReleaseOnly(sem, opt.Format, opt.AllowBuildInRelease, opt.fallbackPre())
+		ws.trace.dropBy(sem, gateReason)
+		// non-semver are dropped in ReleaseOnly mode, floating tags aside
+		other = keepFloating(other, opt.FloatingTags)
 	} else if opt.FilterSemver {
-		// keep only valid semver
-		other = nil
+		// keep only valid semver and floating tags
+		other = keepFloating(other, opt.FloatingTags)
+	}
+
+	// Prereleases only (release gating above wins)
+	if opt.PrereleaseOnly && opt.Format == FormatNone {
+		ws.trace.mark(sem)
+		sem = filterPrerelease(sem)
+		ws.trace.drop(sem, "prerelease")
+	}
+
+	// Prerelease channel (rc/beta/...)
+	if len(opt.PrereleaseChannels) > 0 {
+		ws.trace.mark(sem)
+		sem = filterChannels(sem, opt.PrereleaseChannels)
+		ws.trace.drop(sem, "prerelease")
+	}
+
+	// Full X.Y.Z only
+	if opt.RequireFullVersion {
+		ws.trace.mark(sem)
+		sem = filterFullVersion(sem)
+		ws.trace.drop(sem, "format")
+	}
+
+	// Exact X / X.Y / X.Y.Z form
+	if opt.ExactComponents > 0 {
+		ws.trace.mark(sem)
+		sem = filterExactComponents(sem, opt.ExactComponents)
+		ws.trace.drop(sem, "format")
 	}
 
-	// Range (only for semver)
-	if opt.Range.Enabled() && len(sem) > 0 {
-		sem = applyRange(sem, opt.Range)
+	// Range / Ranges (only for semver)
+	if rs := opt.ranges(); len(rs) > 0 && len(sem) > 0 {
+		ws.trace.mark(sem)
+		sem = applyRanges(sem, rs)
+		ws.trace.drop(sem, "range")
+	}
+
+	// Constraint expression (only for semver), an invalid one is ignored
+	if opt.constraint != nil && len(sem) > 0 {
+		ws.trace.mark(sem)
+		sem = applyConstraint(sem, opt.constraint)
+		ws.trace.drop(sem, "range")
 	}
 
 	// Deduplicate by (X.Y.Z + prerelease), ignoring build
 	if opt.Deduplicate && len(sem) > 0 {
-		sem = deduplicate(sem)
+		ws.trace.mark(sem)
+		sem = deduplicate(sem, opt.DedupPrefer)
+		ws.trace.drop(sem, "dedup")
+	}
+
+	if ws.canceled() {
+		return nil
+	}
+
+	ws.trace.mark(sem)
+	aggregated := false
+
+	// Collapse patches of the same (major, minor), keeping first-seen group order
+	if opt.DedupByMinor && len(sem) > 0 {
+		sem = aggregateBest(sem[:0], sem, minorKey, ws.keyMap())
+		aggregated = true
 	}
 
 	// Depth aggregation (for semver only)
 	if len(sem) > 0 {
 		switch opt.Depth {
-		case DepthPatch:
-
+		case DepthNone, DepthPatch:
+			// no grouping, input order kept
 		case DepthMinor:
-			sem = aggregateMinor(sem)
+			if opt.FallbackPrerelease {
+				sem = dropShadowedPre(sem, minorKey)
+			}
+			if opt.KeepPerGroup > 1 {
+				sem = aggregateTopN(sem, opt.KeepPerGroup, minorKey)
+			} else {
+				sem = aggregateBest(sem[:0], sem, minorKey, ws.keyMap())
+			}
+			aggregated = true
 		case DepthMajor:
-			sem = aggregateMajor(sem)
+			if opt.FallbackPrerelease {
+				sem = dropShadowedPre(sem, majorKey)
+			}
+			if opt.KeepPerGroup > 1 {
+				sem = aggregateTopN(sem, opt.KeepPerGroup, majorKey)
+			} else {
+				sem = aggregateBest(sem[:0], sem, majorKey, ws.keyMap())
+			}
+			aggregated = true
 		case DepthLatest:
-			sem = aggregateLatest(sem)
-		default: // DepthPatch -> keep all
+			if opt.FallbackPrerelease {
+				sem = dropShadowedPre(sem, latestKey)
+			}
+			if opt.KeepPerGroup > 1 {
+				sem = aggregateTopN(sem, opt.KeepPerGroup, latestKey)
+			} else {
+				sem = aggregateLatest(sem)
+			}
+			aggregated = true
+		case DepthMajorChannels:
+			sem = aggregateMajorChannels(sem)
+			aggregated = true
+		default: // unknown -> keep all
 		}
 	}
+	ws.trace.drop(sem, "aggregated")
 
-	// Sort
+	// Uniform 'v' on aggregation winners
+	if aggregated && opt.NormalizeAggregatedPrefix != PrefixAny {
+		for i := range sem {
+			sem[i].raw = setVPrefix(sem[i].raw, opt.NormalizeAggregatedPrefix)
+		}
+	}
+
+	if ws.canceled() {
+		return nil
+	}
+
+	// Sort (a no-op for the single record of DepthLatest)
 	switch opt.Sort {
-	case SortAsc:
-		sortSemver(sem, true)
-		sortStrings(other, true)
-	case SortDesc:
-		sortSemver(sem, false)
-		sortStrings(other, false)
+	case SortAsc, SortDesc:
+		asc := opt.Sort == SortAsc
+		if top > 0 && top < len(sem) {
+			other = nil // only the top records are output, non-semver come after them (see sortLimit)
+		}
+		sem = topSemver(sem, top, asc, opt.sortTie())
+		sortStrings(other, asc)
+	case SortReverse:
+		slices.Reverse(sem)
+		slices.Reverse(other)
+	case SortNatural:
+		sortSemver(sem, true, opt.sortTie())
+		sortNatural(other)
 	default:
 		// keep original order (stable by idx)
 	}
 
-	// Join semver first, then non-semver (when kept)
-	render := make([]string, 0, len(sem)+len(other))
-	if opt.OutputCanonical {
-		for _, r := range sem {
-			render = append(render, r.ver.Canonical())
-		}
-	} else if opt.OutputSemVer {
-		for _, r := range sem {
-			render = append(render, r.ver.SemVer())
-		}
+	// Join semver and non-semver (when kept) in NonSemverPlacement order
+	out := grab(ws.out, len(sem)+len(other))
+	if opt.NonSemverPlacement == NonSemverPrepend {
+		out = append(joinRecs(out, nil, other), sem...)
 	} else {
-		for _, r := range sem {
-			render = append(render, r.raw)
-		}
+		out = joinRecs(out, sem, other)
+	}
+	ws.out = out
+
+	return out
+}
+
+// selectLimited is selectRecs with Offset/Limit/LimitFromEnd (and LimitSpreadMajors) applied.
+func selectLimited(in []string, opt Options) []rec {
+	return limitRecs(selectRecsWith(in, opt, &workspace{}, sortLimit(opt)), opt)
+}
+
+// sortLimit is how many leading records limitRecs can output (Offset+Limit),
+// or 0 when it may need all of them (also when non-semver tags lead or
+// DedupOutput may drop some of the leading ones).
+func sortLimit(opt Options) int {
+	if opt.Limit <= 0 || opt.LimitFromEnd || opt.LimitSpreadMajors ||
+		opt.NonSemverPlacement == NonSemverPrepend || opt.DedupOutput {
+		return 0
+	}
+
+	return max(opt.Offset, 0) + opt.Limit
+}
+
+// joinRecs appends sem, then non-semver raw strings as records without a
+// parsed version, to out.
+func joinRecs(out, sem []rec, other []string) []rec {
+	out = append(out, sem...)
+	for _, s := range other {
+		out = append(out, rec{raw: s, idx: -1})
 	}
-	render = append(render, other...)
 
-	// Limit
-	return capStrings(render, opt.Limit)
+	return out
 }
 
 // Releases runs Select with DefaultOptions.
@@ -148,6 +531,18 @@ func LatestPerMajor(in []string) []string {
 	return Select(in, opt)
 }
 
+// Prereleases runs Select with PrereleaseOptions: every prerelease,
+// newest first. Equivalent to Select(in, PrereleaseOptions()).
+func Prereleases(in []string) []string {
+	return Select(in, PrereleaseOptions())
+}
+
+// EveryVersion runs Select with AllVersionsOptions: every SemVer version,
+// newest first. Equivalent to Select(in, AllVersionsOptions()).
+func EveryVersion(in []string) []string {
+	return Select(in, AllVersionsOptions())
+}
+
 // ReleasesCanonical is like Releases but returns canonical strings
 // ("vMAJOR.MINOR.PATCH") in the output.
 func ReleasesCanonical(in []string) []string {
@@ -156,3 +551,53 @@ func ReleasesCanonical(in []string) []string {
 
 	return Select(in, opt)
 }
+
+// ChangelogSkeleton returns a "## vX.Y.Z" header per selected version in the
+// (from, to] window, ascending. Empty from/to leave that side open.
+// opt.Range, opt.Ranges and opt.Sort are overridden; all other options apply as in Select.
+func ChangelogSkeleton(in []string, from, to string, opt Options) []string {
+	opt.Range = Range{Min: from, MinExclusive: true, Max: to}
+	opt.Ranges = nil
+	opt.Sort = SortAsc
+
+	vs := SelectParsed(in, opt)
+	out := make([]string, 0, len(vs))
+	for i := range vs {
+		out = append(out, "## "+vs[i].Canonical())
+	}
+
+	return out
+}
+
+// ShorthandWarning reports a shorthand tag ("1" or "1.2") selected next to
+// full X.Y.Z versions of the same series, which makes rolling pointers ambiguous.
+type ShorthandWarning struct {
+	// Shorthand is the raw shorthand tag.
+	Shorthand string
+
+	// Full lists the raw full versions of the same major (for "X") or
+	// major.minor (for "X.Y") in input order.
+	Full []string
+}
+
+// SelectStrict is Select plus diagnostics: when release gating (Format) lets
+// shorthand tags through, it warns about every shorthand whose series also has
+// full versions among the gated candidates (before Range, aggregation and Limit).
+// Set RequireFullVersion to drop such shorthands.
+func SelectStrict(in []string, opt Options) ([]string, []ShorthandWarning) {
+	out := Select(in, opt)
+
+	opt = opt.normalized()
+	if opt.Format&(FormatX|FormatXY) == 0 || opt.RequireFullVersion {
+		return out, nil
+	}
+
+	cOpt := opt
+	cOpt.Range = Range{}
+	cOpt.Ranges = nil
+	cOpt.Depth = DepthPatch
+	cOpt.DedupByMinor = false
+	cOpt.Sort = SortNone
+
+	return out, shorthandConflicts(selectRecs(in, cOpt))
+}
@@ -3,8 +3,11 @@ package rats
 import (
 	"math/rand"
 	"regexp"
+	"sort"
 	"strconv"
 	"testing"
+
+	"github.com/woozymasta/semver"
 )
 
 // global sink
@@ -283,6 +286,54 @@ func Benchmark_Select_ReleaseOnly_DepthLatest(b *testing.B) {
 	}
 }
 
+// DepthLatest leaves a single SemVer record, so the Sort step is a no-op
+// (sortSemver returns early): both variants should cost the same.
+
+func Benchmark_Select_DepthLatest100k_SortNone(b *testing.B) {
+	benchLatest(b, SortNone)
+}
+
+func Benchmark_Select_DepthLatest100k_SortDesc(b *testing.B) {
+	benchLatest(b, SortDesc)
+}
+
+func benchLatest(b *testing.B, sm SortMode) {
+	b.ReportAllocs()
+	tags := makeTags(100000)
+
+	opt := Options{FilterSemver: true, Depth: DepthLatest, Sort: sm}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResult = Select(tags, opt)
+	}
+}
+
+// shorthand forms are classified from semver.Parse flags, no regex involved
+func Benchmark_Select_ReleaseOnly_Shorthand100k(b *testing.B) {
+	b.ReportAllocs()
+	raw := make([]string, 0, 100000)
+
+	r := rand.New(rand.NewSource(5))
+	for len(raw) < cap(raw) {
+		s := strconv.Itoa(r.Intn(50))
+		switch r.Intn(3) {
+		case 1:
+			s += "." + strconv.Itoa(r.Intn(50))
+		case 2:
+			s += "." + strconv.Itoa(r.Intn(50)) + "." + strconv.Itoa(r.Intn(50))
+		}
+		raw = append(raw, s)
+	}
+
+	opt := Options{Format: FormatAll, Sort: SortAsc}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResult = Select(raw, opt)
+	}
+}
+
 // * Sort variants
 
 func Benchmark_Select_SortSemverAsc(b *testing.B) {
@@ -306,6 +357,86 @@ func Benchmark_Select_SortSemverAsc(b *testing.B) {
 	}
 }
 
+// Select sorts the records it already parsed; the "string resort" variant
+// mimics a Filter-then-Sort([]string) pipeline that parses every tag twice.
+
+func Benchmark_Select_Sort80k(b *testing.B) {
+	b.ReportAllocs()
+	tags := makeTags(80000)
+
+	opt := Options{FilterSemver: true, Sort: SortDesc}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResult = Select(tags, opt)
+	}
+}
+
+func Benchmark_Select_Sort80k_StringResort(b *testing.B) {
+	b.ReportAllocs()
+	tags := makeTags(80000)
+
+	opt := Options{FilterSemver: true}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out := Select(tags, opt)
+		sort.SliceStable(out, func(x, y int) bool {
+			a, _ := semver.Parse(out[x])
+			b, _ := semver.Parse(out[y])
+			return a.Compare(b) > 0
+		})
+		benchResult = out
+	}
+}
+
+// * parsing
+
+func Benchmark_Select_Parse100k_Serial(b *testing.B) {
+	benchParse(b, 0)
+}
+
+func Benchmark_Select_Parse100k_Parallel(b *testing.B) {
+	benchParse(b, -1)
+}
+
+func benchParse(b *testing.B, workers int) {
+	b.ReportAllocs()
+	tags := makeTags(100000)
+
+	opt := Options{FilterSemver: true, Parallelism: workers}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResult = Select(tags, opt)
+	}
+}
+
+// * Selector (reused buffers)
+
+func Benchmark_Select_DefaultOptions(b *testing.B) {
+	b.ReportAllocs()
+	tags := makeTags(tagsCount)
+	opt := DefaultOptions()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResult = Select(tags, opt)
+	}
+}
+
+func Benchmark_Selector_DefaultOptions(b *testing.B) {
+	b.ReportAllocs()
+	tags := makeTags(tagsCount)
+	opt := DefaultOptions()
+	s := NewSelector()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResult = s.Select(tags, opt)
+	}
+}
+
 // * Select + Limit
 
 func Benchmark_Select_WithLimit(b *testing.B) {
@@ -321,6 +452,33 @@ func Benchmark_Select_WithLimit(b *testing.B) {
 	}
 }
 
+// Limit=10 over 100k tags keeps a bounded heap instead of sorting everything;
+// the FullSort variant sorts all and truncates (the former path).
+
+func Benchmark_Select_WithLimit10_100k(b *testing.B) {
+	b.ReportAllocs()
+	tags := makeTags(100000)
+
+	opt := Options{FilterSemver: true, Sort: SortDesc, Limit: 10}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResult = Select(tags, opt)
+	}
+}
+
+func Benchmark_Select_WithLimit10_100k_FullSort(b *testing.B) {
+	b.ReportAllocs()
+	tags := makeTags(100000)
+
+	opt := Options{FilterSemver: true, Sort: SortDesc}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResult = Select(tags, opt)[:10]
+	}
+}
+
 // * prefilters
 
 func Benchmark_PrefilterSignatures(b *testing.B) {
@@ -0,0 +1,411 @@
+package rats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSelectParsed(t *testing.T) {
+	in := []string{"v1.2.3", "latest", "1.10.0", "2.0.0-rc.1", "1.2"}
+	opt := Options{Sort: SortDesc}
+
+	got := SelectParsed(in, opt)
+	want := []string{"2.0.0-rc.1", "1.10.0", "v1.2.3", "1.2"}
+	if len(got) != len(want) {
+		t.Fatalf("len=%d, want %d: %v", len(got), len(want), got)
+	}
+
+	for i, v := range got {
+		if !v.Valid || v.Original != want[i] {
+			t.Fatalf("at %d: got %q (valid=%v), want %q", i, v.Original, v.Valid, want[i])
+		}
+	}
+
+	// same order as Select for the semver part
+	eqStrings(t, Select(in, opt)[:len(want)], want)
+
+	if got[0].Major != 2 || got[0].Prerelease != "rc.1" {
+		t.Fatalf("parsed fields lost: %+v", got[0])
+	}
+}
+
+func TestSelectParsed_LimitAndEmpty(t *testing.T) {
+	in := []string{"1.0.0", "2.0.0", "3.0.0", "foo"}
+
+	got := SelectParsed(in, Options{Sort: SortDesc, Limit: 2})
+	if len(got) != 2 || got[0].Original != "3.0.0" || got[1].Original != "2.0.0" {
+		t.Fatalf("limit: got %v", got)
+	}
+
+	if got := SelectParsed([]string{"foo", "bar"}, Options{}); len(got) != 0 {
+		t.Fatalf("non-semver only: got %v", got)
+	}
+}
+
+func TestSelectPage(t *testing.T) {
+	in := []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0"}
+	opt := Options{Sort: SortDesc}
+
+	cases := []struct {
+		page, size int
+		want       []string
+	}{
+		{1, 2, []string{"1.4.0", "1.3.0"}},
+		{2, 2, []string{"1.2.0", "1.1.0"}},
+		{3, 2, []string{"1.0.0"}},
+		{4, 2, []string{}},
+		{0, 2, []string{}},
+		{1, 0, []string{}},
+		{1, 10, []string{"1.4.0", "1.3.0", "1.2.0", "1.1.0", "1.0.0"}},
+	}
+
+	for _, c := range cases {
+		items, total := SelectPage(in, opt, c.page, c.size)
+		if total != 5 {
+			t.Fatalf("page=%d size=%d: total=%d, want 5", c.page, c.size, total)
+		}
+		if items == nil {
+			t.Fatalf("page=%d size=%d: items is nil", c.page, c.size)
+		}
+		eqStrings(t, items, c.want)
+	}
+
+	items, total := SelectPage(nil, opt, 1, 10)
+	if total != 0 || len(items) != 0 {
+		t.Fatalf("empty input: items=%v total=%d", items, total)
+	}
+}
+
+func TestChangelogSkeleton(t *testing.T) {
+	in := []string{"1.0.0", "v1.1.0", "1.2", "1.3.0-rc.1", "1.3.0", "2.0.0", "latest"}
+	opt := DefaultOptions()
+	opt.Depth = DepthPatch
+
+	got := ChangelogSkeleton(in, "1.0.0", "1.3.0", opt)
+	eqStrings(t, got, []string{"## v1.1.0", "## v1.2.0", "## v1.3.0"})
+
+	// open upper bound, prereleases allowed by options
+	got = ChangelogSkeleton(in, "1.2.0", "", Options{FilterSemver: true})
+	eqStrings(t, got, []string{"## v1.3.0-rc.1", "## v1.3.0", "## v2.0.0"})
+}
+
+func TestSelectStrict(t *testing.T) {
+	in := []string{"1", "1.2", "1.2.0", "1.2.3", "1.3.0", "2", "2.0", "3.1", "1.4.0-rc.1"}
+	opt := Options{Format: FormatAll, Sort: SortDesc}
+
+	out, warns := SelectStrict(in, opt)
+	eqStrings(t, out, Select(in, opt))
+
+	if len(warns) != 2 {
+		t.Fatalf("warnings=%d, want 2: %+v", len(warns), warns)
+	}
+
+	if warns[0].Shorthand != "1" {
+		t.Fatalf("first warning=%+v", warns[0])
+	}
+	eqStrings(t, warns[0].Full, []string{"1.2.0", "1.2.3", "1.3.0"})
+
+	if warns[1].Shorthand != "1.2" {
+		t.Fatalf("second warning=%+v", warns[1])
+	}
+	eqStrings(t, warns[1].Full, []string{"1.2.0", "1.2.3"})
+
+	// no shorthand allowed -> nothing to warn about
+	if _, warns := SelectStrict(in, Options{Format: FormatAll, RequireFullVersion: true}); len(warns) != 0 {
+		t.Fatalf("RequireFullVersion: got %+v", warns)
+	}
+	if _, warns := SelectStrict(in, Options{FilterSemver: true}); len(warns) != 0 {
+		t.Fatalf("no release gating: got %+v", warns)
+	}
+}
+
+func TestSelectVersions(t *testing.T) {
+	in := []string{"1.0.0", "foo", "v2.0.0"}
+
+	got := SelectVersions(in, Options{Sort: SortDesc})
+	if len(got) != 2 || got[0].Original != "v2.0.0" || got[1].Original != "1.0.0" {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+// TestSelect_Smoke runs the public entry points end to end, including
+// OutputSemVer and the FormatNone (no release gating) path.
+func TestSelect_Smoke(t *testing.T) {
+	in := []string{"v1.2.3+b1", "1.3.0-rc.1", "1.2", "latest"}
+
+	eqStrings(t, Select(in, Options{}), in)
+	eqStrings(t, Select(in, Options{Format: FormatNone, Sort: SortDesc}),
+		[]string{"1.3.0-rc.1", "v1.2.3+b1", "1.2", "latest"})
+	eqStrings(t, Select(in, Options{FilterSemver: true, OutputSemVer: true, Sort: SortAsc}),
+		[]string{"1.2.0", "1.2.3+b1", "1.3.0-rc.1"})
+	eqStrings(t, Select(in, DefaultOptions()), []string{"1.2"}) // build metadata is not a release
+}
+
+func TestSelect_OffsetLimitFromEnd(t *testing.T) {
+	in := []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0", "1.5.0", "1.6.0"}
+	run := func(o Options) []string {
+		o.Sort = SortDesc
+		return Select(in, o)
+	}
+
+	// 3rd..5th newest
+	eqStrings(t, run(Options{Offset: 2, Limit: 3}), []string{"1.4.0", "1.3.0", "1.2.0"})
+	// oldest two
+	eqStrings(t, run(Options{Limit: 2, LimitFromEnd: true}), []string{"1.1.0", "1.0.0"})
+	// tail after offset
+	eqStrings(t, run(Options{Offset: 5}), []string{"1.1.0", "1.0.0"})
+	eqStrings(t, run(Options{Offset: 1, Limit: 2, LimitFromEnd: true}), []string{"1.1.0", "1.0.0"})
+	// guards
+	eqStrings(t, run(Options{Offset: -3, Limit: 1}), []string{"1.6.0"})
+	eqStrings(t, run(Options{Offset: 100}), []string{})
+	eqStrings(t, run(Options{Offset: 1 << 62, Limit: 1 << 62}), []string{})
+	eqStrings(t, run(Options{Limit: 100, LimitFromEnd: true}), []string{"1.6.0", "1.5.0", "1.4.0", "1.3.0", "1.2.0", "1.1.0", "1.0.0"})
+}
+
+func TestSelector_MatchesSelect(t *testing.T) {
+	inputs := [][]string{
+		makeTags(3000),
+		{"v1.2.3", "1.2.3+b1", "1.2", "2.0.0-rc.1", "latest", "1.3.0"},
+		{"b", "a", "c"},
+		nil,
+		makeTags(500),
+	}
+	opts := []Options{
+		{},
+		DefaultOptions(),
+		{Depth: DepthMajor, Sort: SortAsc, Deduplicate: true},
+		{DedupByMinor: true, Depth: DepthMinor, Sort: SortDesc, Limit: 7},
+		{Depth: DepthLatest, FilterSemver: true},
+		{Sort: SortDesc, OutputCanonical: true, Offset: 3},
+	}
+
+	var s Selector
+	for round := 0; round < 2; round++ {
+		for _, in := range inputs {
+			for _, opt := range opts {
+				eqStrings(t, s.Select(in, opt), Select(in, opt))
+			}
+		}
+	}
+}
+
+func TestSelect_TopKMatchesFullSort(t *testing.T) {
+	in := makeTags(4000)
+	in = append(in, "1.2.3", "v1.2.3", "1.2.3+b1", "1.2.3", "v1.2.3+b0")
+
+	for _, sm := range []SortMode{SortAsc, SortDesc} {
+		for _, tb := range []TieBreak{TieLexical, TieShortest, TieInputOrder} {
+			for _, lim := range []int{1, 3, 10, 250} {
+				for _, off := range []int{0, 7} {
+					opt := Options{Sort: sm, TieBreak: tb}
+					full := Select(in, opt)
+
+					opt.Limit, opt.Offset = lim, off
+					eqStrings(t, Select(in, opt), full[off:off+lim])
+				}
+			}
+		}
+	}
+
+	// fewer semver than the limit: non-semver follow as before
+	small := []string{"b", "2.0.0", "a", "1.0.0"}
+	eqStrings(t, Select(small, Options{Sort: SortDesc, Limit: 3}), []string{"2.0.0", "1.0.0", "b"})
+}
+
+// countdownCtx reports Canceled from the n-th Err call on.
+type countdownCtx struct {
+	context.Context
+	n int
+}
+
+func (c *countdownCtx) Err() error {
+	if c.n--; c.n <= 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestSelectContext(t *testing.T) {
+	in := makeTags(3*cancelStep + 5)
+	opt := Options{FilterSemver: true, Sort: SortDesc, Depth: DepthMinor}
+
+	got, err := SelectContext(context.Background(), in, opt)
+	if err != nil {
+		t.Fatalf("SelectContext: %v", err)
+	}
+	eqStrings(t, got, Select(in, opt))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := SelectContext(ctx, in, opt); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled: err=%v", err)
+	}
+
+	// canceled in the middle of parsing, after the prefilter chunks
+	for _, n := range []int{3, 6, 9} {
+		got, err := SelectContext(&countdownCtx{Context: context.Background(), n: n}, in, opt)
+		if !errors.Is(err, context.Canceled) || got != nil {
+			t.Fatalf("countdown %d: got %d tags, err=%v", n, len(got), err)
+		}
+	}
+
+	if _, err := SelectContext(context.Background(), in, Options{Constraint: ">=1.y"}); err == nil {
+		t.Fatalf("want config error")
+	}
+}
+
+func TestPresets(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"v1.0.0", "1.1.0-rc.1", "v1.1.0-rc.1", "1.1.0", "2.0.0-beta", "latest", "2.0.0-beta.2"}
+
+	eqStrings(t, Prereleases(in), []string{"2.0.0-beta.2", "2.0.0-beta", "1.1.0-rc.1"})
+	eqStrings(t, EveryVersion(in), []string{"2.0.0-beta.2", "2.0.0-beta", "1.1.0", "1.1.0-rc.1", "v1.0.0"})
+
+	opt := AllVersionsOptions()
+	opt.Depth = DepthMajor
+	eqStrings(t, Select(in, opt), []string{"2.0.0-beta.2", "1.1.0"})
+}
+
+func TestSelect_EmptyResultNotNil(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		in  []string
+		opt Options
+	}{
+		"nil input":        {nil, DefaultOptions()},
+		"empty input":      {[]string{}, Options{}},
+		"prefilter":        {[]string{"v1.2.3"}, Options{VPrefix: PrefixNone}},
+		"no semver":        {[]string{"latest", "edge"}, Options{FilterSemver: true}},
+		"string-only path": {[]string{"latest", "edge"}, Options{Exclude: regexp.MustCompile(`^(latest|edge)$`)}},
+		"semver filtered":  {[]string{"1.2.3"}, Options{Range: Range{Min: "2"}}},
+		"offset past end":  {[]string{"1.2.3", "latest"}, Options{Offset: 5}},
+		"template":         {[]string{"latest"}, Options{FilterSemver: true, OutputTemplate: "{{.Major}}"}},
+	}
+	for name, c := range cases {
+		out := Select(c.in, c.opt)
+		if out == nil || len(out) != 0 {
+			t.Errorf("%s: Select = %#v, want empty non-nil", name, out)
+		}
+
+		out, err := SelectErr(c.in, c.opt)
+		if err != nil || out == nil || len(out) != 0 {
+			t.Errorf("%s: SelectErr = %#v, %v", name, out, err)
+		}
+
+		if b, _ := json.Marshal(Select(c.in, c.opt)); string(b) != "[]" {
+			t.Errorf("%s: JSON = %s, want []", name, b)
+		}
+	}
+}
+
+// Depth aggregation with SortNone keeps groups in first-seen order, the
+// same through every entry point.
+func TestDepth_SortNoneOrderAcrossEntryPoints(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"1.2.0", "2.0.1", "1.2.5", "0.9.0", "2.1.0", "1.3.0", "2.0.3", "0.9.1", "edge"}
+	want := map[Depth][]string{
+		DepthMinor:  {"1.2.5", "2.0.3", "0.9.1", "2.1.0", "1.3.0", "edge"},
+		DepthMajor:  {"1.3.0", "2.1.0", "0.9.1", "edge"},
+		DepthLatest: {"2.1.0", "edge"},
+	}
+
+	for depth, w := range want {
+		opt := Options{Depth: depth}
+
+		eqStrings(t, Select(in, opt), w)
+
+		got, err := SelectContext(context.Background(), in, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eqStrings(t, got, w)
+		eqStrings(t, NewSelector().Select(in, opt), w)
+
+		var b strings.Builder
+		if err := SelectStream(strings.NewReader(strings.Join(in, "\n")), &b, opt); err != nil {
+			t.Fatal(err)
+		}
+		eqStrings(t, strings.Fields(b.String()), w)
+
+		var detailed []string
+		for _, v := range SelectDetailed(in, opt) {
+			detailed = append(detailed, v.Rendered)
+		}
+		eqStrings(t, detailed, w)
+
+		var parsed []string
+		for _, v := range SelectParsed(in, opt) {
+			parsed = append(parsed, v.Original)
+		}
+		eqStrings(t, parsed, w[:len(w)-1]) // non-semver omitted
+	}
+}
+
+func TestNonSemverPlacement(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"1.0.0", "latest", "2.0.0", "stable"}
+
+	cases := []struct {
+		place NonSemverPlacement
+		limit int
+		want  []string
+	}{
+		{NonSemverAppend, 0, []string{"2.0.0", "1.0.0", "stable", "latest"}},
+		{NonSemverPrepend, 0, []string{"stable", "latest", "2.0.0", "1.0.0"}},
+		{NonSemverPrepend, 3, []string{"stable", "latest", "2.0.0"}},
+		{NonSemverDrop, 0, []string{"2.0.0", "1.0.0"}},
+	}
+	for _, c := range cases {
+		opt := Options{Sort: SortDesc, NonSemverPlacement: c.place, Limit: c.limit}
+		eqStrings(t, Select(in, opt), c.want)
+
+		var b strings.Builder
+		if err := SelectStream(strings.NewReader(strings.Join(in, "\n")), &b, opt); err != nil {
+			t.Fatal(err)
+		}
+		eqStrings(t, strings.Fields(b.String()), c.want)
+	}
+
+	// input order kept within each part
+	eqStrings(t, Select(in, Options{NonSemverPlacement: NonSemverPrepend}), []string{"latest", "stable", "1.0.0", "2.0.0"})
+	eqStrings(t, Select([]string{"latest"}, Options{NonSemverPlacement: NonSemverDrop}), []string{})
+}
+
+func TestDedupOutput(t *testing.T) {
+	t.Parallel()
+
+	in := []string{"1", "1.0.0", "v1.0", "1.1.0", "1.1"}
+
+	opt := Options{OutputCanonical: true}
+	eqStrings(t, Select(in, opt), []string{"v1.0.0", "v1.0.0", "v1.0.0", "v1.1.0", "v1.1.0"})
+	eqStrings(t, SelectUnique(in, opt), []string{"v1.0.0", "v1.1.0"})
+
+	// Limit counts unique strings, on every entry point
+	opt = Options{OutputCanonical: true, DedupOutput: true, Limit: 2, Sort: SortAsc}
+	eqStrings(t, Select(in, opt), []string{"v1.0.0", "v1.1.0"})
+	eqStrings(t, NewSelector().Select(in, opt), []string{"v1.0.0", "v1.1.0"})
+
+	var b strings.Builder
+	if err := SelectStream(strings.NewReader(strings.Join(in, "\n")), &b, Options{OutputCanonical: true, DedupOutput: true}); err != nil {
+		t.Fatal(err)
+	}
+	eqStrings(t, strings.Fields(b.String()), []string{"v1.0.0", "v1.1.0"})
+
+	// detailed output keeps the first record of every rendered string
+	vis := SelectDetailed(in, opt)
+	if len(vis) != 2 || vis[0].Raw != "1" || vis[1].Rendered != "v1.1.0" {
+		t.Fatalf("SelectDetailed=%+v", vis)
+	}
+
+	// templates too
+	opt = Options{FilterSemver: true, DedupOutput: true, OutputTemplate: "{{.Major}}"}
+	eqStrings(t, Select(in, opt), []string{"1"})
+}
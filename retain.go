@@ -0,0 +1,70 @@
+package rats
+
+// RetentionPolicy describes which versions a registry GC job keeps.
+// Zero values keep as little as possible: a zero policy keeps the newest
+// version of every minor series.
+type RetentionPolicy struct {
+	// NewestMinors is how many of the newest (major, minor) series keep
+	// PatchesPerMinor versions. 0 means every series.
+	NewestMinors int
+
+	// PatchesPerMinor is how many newest versions each of those series
+	// keeps. Values below 1 mean 1.
+	PatchesPerMinor int
+
+	// MajorsKept additionally keeps the newest version of each of the
+	// MajorsKept newest majors (so older majors are not lost entirely). 0 disables.
+	MajorsKept int
+}
+
+// Retain splits in into tags to keep and tags to drop (what a GC job deletes)
+// according to p. Versions are compared by SemVer identity, so every alias
+// ("1.2.3", "v1.2.3", "1.2.3+b1") shares the fate of its version; prereleases
+// count as versions of their series. Non-semver tags are always kept.
+//
+// Both lists are ordered newest first (aliases in input order), with
+// non-semver tags appended to keep in input order.
+func Retain(in []string, p RetentionPolicy) (keep, drop []string) {
+	rs, _ := parseAll(in)
+	sem, other := splitSemver(rs)
+
+	sortSemver(sem, false, TieInputOrder)
+	distinct := deduplicate(append([]rec(nil), sem...), PreferFirstSeen)
+
+	kept := make(map[dkey]struct{}, len(distinct))
+
+	// newest minors, PatchesPerMinor each
+	minors := make(map[uint64]struct{}, 16)
+	for _, r := range aggregateMinor(distinct) {
+		if p.NewestMinors > 0 && len(minors) >= p.NewestMinors {
+			break
+		}
+		minors[minorKey(r.ver)] = struct{}{}
+	}
+
+	for _, r := range aggregateTopN(distinct, max(p.PatchesPerMinor, 1), minorKey) {
+		if _, ok := minors[minorKey(r.ver)]; ok {
+			kept[keyOf(r.ver)] = struct{}{}
+		}
+	}
+
+	// newest of the newest majors
+	for i, r := range aggregateMajor(distinct) {
+		if i >= p.MajorsKept {
+			break
+		}
+		kept[keyOf(r.ver)] = struct{}{}
+	}
+
+	keep = make([]string, 0, len(kept)+len(other))
+	drop = make([]string, 0, len(sem))
+	for _, r := range sem {
+		if _, ok := kept[keyOf(r.ver)]; ok {
+			keep = append(keep, r.raw)
+		} else {
+			drop = append(drop, r.raw)
+		}
+	}
+
+	return append(keep, other...), drop
+}
@@ -0,0 +1,26 @@
+package rats
+
+import "testing"
+
+func TestRetain(t *testing.T) {
+	in := []string{
+		"latest", "edge",
+		"1.0.0", "1.0.1", "1.1.0",
+		"2.0.0", "2.0.1", "2.1.0", "2.1.1", "2.1.2", "v2.1.2",
+		"3.0.0", "3.0.1", "3.0.2", "3.0.3", "3.1.0-rc.1",
+	}
+
+	// keep the 3 newest of the 2 newest minors, plus the newest of 3 majors
+	keep, drop := Retain(in, RetentionPolicy{NewestMinors: 2, PatchesPerMinor: 3, MajorsKept: 3})
+	eqStrings(t, keep, []string{"3.1.0-rc.1", "3.0.3", "3.0.2", "3.0.1", "2.1.2", "v2.1.2", "1.1.0", "latest", "edge"})
+	eqStrings(t, drop, []string{"3.0.0", "2.1.1", "2.1.0", "2.0.1", "2.0.0", "1.0.1", "1.0.0"})
+
+	// zero policy: newest of every minor
+	keep, drop = Retain(in, RetentionPolicy{})
+	eqStrings(t, keep, []string{"3.1.0-rc.1", "3.0.3", "2.1.2", "v2.1.2", "2.0.1", "1.1.0", "1.0.1", "latest", "edge"})
+	eqStrings(t, drop, []string{"3.0.2", "3.0.1", "3.0.0", "2.1.1", "2.1.0", "2.0.0", "1.0.0"})
+
+	if len(keep)+len(drop) != len(in) {
+		t.Fatalf("keep+drop must cover the input")
+	}
+}
@@ -0,0 +1,102 @@
+package rats
+
+import "context"
+
+// Selector runs Select reusing its internal buffers (raw prefilter, parsed
+// records, aggregation maps) between calls, which cuts allocations and GC
+// churn for callers selecting in a hot loop, e.g. once per request.
+// Results are identical to Select; the returned slices are never reused.
+//
+// The zero value is ready to use. A Selector is not safe for concurrent use:
+// guard it with a mutex or keep one per goroutine (e.g. in a sync.Pool).
+// Its buffers grow to the largest input seen and keep references to the
+// tags of the last call until the next one.
+type Selector struct {
+	ws workspace
+}
+
+// NewSelector returns an empty Selector.
+func NewSelector() *Selector {
+	return &Selector{}
+}
+
+// Select is Select (tolerant of misconfiguration) using the reusable buffers.
+func (s *Selector) Select(in []string, opt Options) []string {
+	opt = opt.normalized()
+
+	out, err := selectOutWith(in, opt, &s.ws)
+	if err != nil {
+		opt.OutputTemplate = ""
+		out, _ = selectOutWith(in, opt, &s.ws)
+	}
+
+	return out
+}
+
+// SelectErr is SelectErr using the reusable buffers.
+func (s *Selector) SelectErr(in []string, opt Options) ([]string, error) {
+	opt = opt.normalized()
+	if opt.err != nil {
+		return nil, opt.err
+	}
+
+	return selectOutWith(in, opt, &s.ws)
+}
+
+// workspace holds the pipeline buffers; a zero workspace allocates as needed.
+// With ctx set the pipeline checks it in steps and stops with err.
+type workspace struct {
+	ctx   context.Context
+	err   error
+	keys  map[uint64]int
+	raw   []string
+	other []string
+	recs  []rec
+	sem   []rec
+	out   []rec
+	trace *tracer // Explain only
+}
+
+// cancelStep is how many tags are prefiltered or parsed between ctx checks.
+const cancelStep = 16384
+
+// step returns the chunk size for n tags: all at once without a context.
+func (ws *workspace) step(n int) int {
+	if ws.ctx == nil {
+		return max(n, 1)
+	}
+
+	return cancelStep
+}
+
+// canceled records and reports the end of ws.ctx.
+func (ws *workspace) canceled() bool {
+	if ws.ctx == nil {
+		return false
+	}
+	if ws.err == nil {
+		ws.err = ws.ctx.Err()
+	}
+
+	return ws.err != nil
+}
+
+// keyMap returns the empty aggregation map.
+func (ws *workspace) keyMap() map[uint64]int {
+	if ws.keys == nil {
+		ws.keys = make(map[uint64]int, 64)
+	} else {
+		clear(ws.keys)
+	}
+
+	return ws.keys
+}
+
+// grab returns b emptied when it can hold n elements, else a new slice.
+func grab[T any](b []T, n int) []T {
+	if cap(b) >= n {
+		return b[:0]
+	}
+
+	return make([]T, 0, n)
+}
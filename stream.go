@@ -0,0 +1,242 @@
+package rats
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// maxStreamLine is the longest accepted input line (one tag).
+const maxStreamLine = 10 * 1024 * 1024
+
+// SelectStream reads newline-delimited tags from r (surrounding spaces
+// trimmed, empty lines skipped), runs the Select pipeline and writes the
+// result to w, one tag per line. Errors are those of SelectErr plus I/O errors.
+//
+// The input is truly streamed, SemVer tags being written as soon as they are
+// read, when every decision is per tag: Sort is SortNone, Depth is DepthNone or
+// DepthPatch, DedupByMinor, LimitFromEnd and LimitSpreadMajors are off and
+// Deduplicate (if set) uses PreferFirstSeen (only a set of seen versions is
+// kept), DedupOutput is off and NonSemverPlacement is not NonSemverPrepend. Non-semver tags are
+// still held back until the end since they follow SemVer tags in the output.
+// Any other combination buffers the whole input.
+func SelectStream(r io.Reader, w io.Writer, opt Options) error {
+	opt = opt.normalized()
+	if opt.err != nil {
+		return opt.err
+	}
+
+	tmpl, err := compileOutputTemplate(opt.OutputTemplate)
+	if err != nil {
+		return err
+	}
+
+	sc := newTagScanner(r, '\n')
+	bw := bufio.NewWriter(w)
+	if streamable(opt) {
+		err = streamTags(sc, bw, opt, tmpl)
+	} else {
+		err = bufferTags(sc, bw, opt)
+	}
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// ScanTags reads sep-delimited tags from r (e.g. '\n', or 0 for NUL-separated
+// input like "xargs -0"), trimming surrounding spaces and skipping empty ones.
+// A single tag may be up to 10 MiB; see ScanTagsLimit.
+func ScanTags(r io.Reader, sep byte) ([]string, error) {
+	return ScanTagsLimit(r, sep, maxStreamLine)
+}
+
+// ScanTagsLimit is ScanTags accepting tags (before trimming) of up to
+// maxLine bytes, or of any length when maxLine <= 0. A longer tag is an
+// error naming its byte offset in r.
+func ScanTagsLimit(r io.Reader, sep byte, maxLine int) ([]string, error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+
+	in := make([]string, 0, 1024)
+	var line []byte
+	var off int64 // offset of line in r
+	for {
+		chunk, err := br.ReadSlice(sep)
+		line = append(line, chunk...)
+
+		n := len(line)
+		if err == nil {
+			n-- // the separator
+		}
+		if maxLine > 0 && n > maxLine {
+			return nil, fmt.Errorf("tag at byte offset %d is longer than %d bytes", off, maxLine)
+		}
+
+		switch err {
+		case bufio.ErrBufferFull:
+			continue
+		case nil, io.EOF:
+		default:
+			return nil, err
+		}
+
+		if s := strings.TrimSpace(string(line[:n])); s != "" {
+			in = append(in, s)
+		}
+
+		off += int64(len(line))
+		line = line[:0]
+
+		if err == io.EOF {
+			return in, nil
+		}
+	}
+}
+
+// newTagScanner returns a scanner splitting r on sep.
+func newTagScanner(r io.Reader, sep byte) *bufio.Scanner {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+	if sep != '\n' {
+		sc.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+			if i := bytes.IndexByte(data, sep); i >= 0 {
+				return i + 1, data[:i], nil
+			}
+			if atEOF && len(data) > 0 {
+				return len(data), data, nil
+			}
+
+			return 0, nil, nil
+		})
+	}
+
+	return sc
+}
+
+// streamable reports whether opt allows a per-tag pipeline.
+func streamable(opt Options) bool {
+	return opt.Sort == SortNone &&
+		(opt.Depth == DepthNone || opt.Depth == DepthPatch) &&
+		!opt.DedupByMinor && !opt.LimitFromEnd && !opt.LimitSpreadMajors &&
+		opt.NonSemverPlacement != NonSemverPrepend && !opt.DedupOutput &&
+		(!opt.Deduplicate || opt.DedupPrefer == PreferFirstSeen)
+}
+
+// bufferTags reads everything and runs the regular pipeline.
+func bufferTags(sc *bufio.Scanner, w *bufio.Writer, opt Options) error {
+	in := make([]string, 0, 1024)
+	for sc.Scan() {
+		if s := strings.TrimSpace(sc.Text()); s != "" {
+			in = append(in, s)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+
+	out, err := selectOut(in, opt)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range out {
+		if err := writeLine(w, s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// streamTags runs the pipeline tag by tag, writing SemVer results at once and
+// non-semver ones at the end, with Offset/Limit counted over the output.
+func streamTags(sc *bufio.Scanner, w *bufio.Writer, opt Options, tmpl *template.Template) error {
+	tagOpt := opt
+	tagOpt.Deduplicate = false // handled with a set across tags
+
+	seen := make(map[dkey]struct{})
+	var other []rec
+
+	skip := max(opt.Offset, 0)
+	left := opt.Limit // <= 0: unlimited
+
+	emit := func(r *rec) (bool, error) {
+		if skip > 0 {
+			skip--
+			return true, nil
+		}
+
+		s := renderRec(r, opt)
+		if tmpl != nil {
+			out, err := renderTemplate([]rec{*r}, tmpl)
+			if err != nil {
+				return false, err
+			}
+			s = out[0]
+		}
+
+		if err := writeLine(w, s); err != nil {
+			return false, err
+		}
+
+		if left > 0 {
+			left--
+			return left > 0, nil
+		}
+
+		return true, nil
+	}
+
+	more := true
+	for more && sc.Scan() {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" {
+			continue
+		}
+
+		for _, r := range selectRecs([]string{s}, tagOpt) {
+			if !r.ver.Valid {
+				other = append(other, r)
+				continue
+			}
+
+			if opt.Deduplicate {
+				k := recKey(&r)
+				if _, ok := seen[k]; ok {
+					continue
+				}
+				seen[k] = struct{}{}
+			}
+
+			var err error
+			if more, err = emit(&r); err != nil || !more {
+				return err
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+
+	for i := range other {
+		more, err := emit(&other[i])
+		if err != nil || !more {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeLine writes s and a newline.
+func writeLine(w *bufio.Writer, s string) error {
+	if _, err := w.WriteString(s); err != nil {
+		return err
+	}
+
+	return w.WriteByte('\n')
+}
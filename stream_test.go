@@ -0,0 +1,89 @@
+package rats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSelectStream_MatchesSelect(t *testing.T) {
+	in := []string{"latest", "v1.2.3", "1.2.3", "1.3.0-rc.1", "edge", "2.0.0", "1.2", "sha256-x", "0.9.0"}
+	src := "  " + strings.Join(in, "\n\n") + "\n"
+
+	opts := []Options{
+		{},
+		{FilterSemver: true},
+		{Deduplicate: true, Limit: 3},
+		{Deduplicate: true, Offset: 2, Limit: 3},
+		{Format: FormatXYZ, OutputCanonical: true},
+		{Range: Range{Min: "1.2"}, Offset: 1},
+		{Sort: SortDesc, Depth: DepthMinor, Limit: 2}, // buffered
+		{Sort: SortAsc, Limit: 2, LimitFromEnd: true}, // buffered
+		{OutputTemplate: "{{.Original}}={{.Semver}}", Limit: 4},
+	}
+
+	for _, opt := range opts {
+		var b bytes.Buffer
+		if err := SelectStream(strings.NewReader(src), &b, opt); err != nil {
+			t.Fatalf("SelectStream(%+v): %v", opt, err)
+		}
+
+		got := strings.Fields(b.String())
+		eqStrings(t, got, Select(in, opt))
+	}
+}
+
+func TestSelectStream_Errors(t *testing.T) {
+	var b bytes.Buffer
+	if err := SelectStream(strings.NewReader("1.0.0\n"), &b, Options{OutputTemplate: "{{"}); err == nil {
+		t.Fatalf("want template error")
+	}
+	if err := SelectStream(strings.NewReader("1.0.0\n"), &b, Options{Constraint: ">=1.y"}); err == nil {
+		t.Fatalf("want constraint error")
+	}
+}
+
+func TestScanTags(t *testing.T) {
+	cases := []struct {
+		src  string
+		want []string
+		sep  byte
+	}{
+		{src: " v1.0.0 \n\n1.2.3\r\nlatest", sep: '\n', want: []string{"v1.0.0", "1.2.3", "latest"}},
+		{src: "v1.0.0\x00\x001.2 3\x00latest\n", sep: 0, want: []string{"v1.0.0", "1.2 3", "latest"}},
+		{src: "", sep: 0, want: nil},
+	}
+
+	for _, c := range cases {
+		got, err := ScanTags(strings.NewReader(c.src), c.sep)
+		if err != nil {
+			t.Fatalf("ScanTags(%q): %v", c.src, err)
+		}
+		eqStrings(t, got, c.want)
+	}
+}
+
+func TestScanTagsLimit(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("a", 200*1024) // several reader buffers
+	src := "1.0.0\n" + long + "\nlatest"
+
+	got, err := ScanTagsLimit(strings.NewReader(src), '\n', 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eqStrings(t, got, []string{"1.0.0", long, "latest"})
+
+	_, err = ScanTagsLimit(strings.NewReader(src), '\n', 1024)
+	if err == nil || !strings.Contains(err.Error(), "offset 6 ") {
+		t.Fatalf("want an offset error, got %v", err)
+	}
+
+	// the limit excludes the separator
+	got, err = ScanTagsLimit(strings.NewReader("12345\n123456"), '\n', 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eqStrings(t, got, []string{"12345", "123456"})
+}
@@ -63,19 +63,132 @@ func joinDash(parts []string) string {
 	}
 }
 
+// hasAnySuffix reports whether s ends with one of suffixes.
+func hasAnySuffix(s string, suffixes []string, fold bool) bool {
+	for _, x := range suffixes {
+		if len(s) < len(x) {
+			continue
+		}
+
+		if fold && strings.EqualFold(s[len(s)-len(x):], x) || !fold && strings.HasSuffix(s, x) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasAnyPrefix reports whether s starts with one of prefixes.
+func hasAnyPrefix(s string, prefixes []string, fold bool) bool {
+	for _, x := range prefixes {
+		if len(s) < len(x) {
+			continue
+		}
+
+		if fold && strings.EqualFold(s[:len(x)], x) || !fold && strings.HasPrefix(s, x) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// defaultSignatureSuffixes is used when Options.SignatureSuffixes is empty.
+var defaultSignatureSuffixes = []string{".sig"}
+
 // isSigTag reports whether s matches "sha256-<64 anycase hex>.sig".
 func isSigTag(s string) bool {
-	// "sha256-" (7) + 64 hex + ".sig" (4) = 75
-	if len(s) != 75 || s[:7] != "sha256-" || s[71:] != ".sig" {
+	return isSigTagWith(s, defaultSignatureSuffixes)
+}
+
+// isHex marks anycase hex digits.
+var isHex = [256]bool{
+	'0': true, '1': true, '2': true, '3': true, '4': true,
+	'5': true, '6': true, '7': true, '8': true, '9': true,
+	'a': true, 'b': true, 'c': true, 'd': true, 'e': true, 'f': true,
+	'A': true, 'B': true, 'C': true, 'D': true, 'E': true, 'F': true,
+}
+
+// isSigTagWith reports whether s is "sha256-<64 hex>" or "sha512-<128 hex>"
+// (anycase hex) followed by exactly one of suffixes (empty means ".sig").
+func isSigTagWith(s string, suffixes []string) bool {
+	if len(suffixes) == 0 {
+		suffixes = defaultSignatureSuffixes
+	}
+
+	// shortest: "sha256-" (7) + 64 hex + 1 byte suffix
+	if len(s) < 72 || s[:3] != "sha" || s[6] != '-' {
+		return false
+	}
+
+	var n int
+	switch s[3:6] {
+	case "256":
+		n = 64
+	case "512":
+		n = 128
+	default:
+		return false
+	}
+
+	if len(s) <= 7+n {
+		return false
+	}
+
+	// suffix first: cheaper than the hex scan
+	suffix := s[7+n:]
+	found := false
+	for _, x := range suffixes {
+		if suffix == x {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return false
+	}
+
+	// check n anycase hex chars
+	for _, c := range []byte(s[7 : 7+n]) {
+		if !isHex[c] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isDigestLike reports whether s is lowercase hex of at least minLen chars
+// with at least one letter (all digits is a valid version, not a digest).
+func isDigestLike(s string, minLen int) bool {
+	if len(s) < minLen {
 		return false
 	}
 
-	// check 64 anycase hex chars
-	for i := 7; i < 71; i++ {
+	letter := false
+	for i := 0; i < len(s); i++ {
 		c := s[i]
-		if (c < '0' || c > '9') &&
-			(c < 'a' || c > 'f') &&
-			(c < 'A' || c > 'F') {
+		switch {
+		case c >= '0' && c <= '9':
+		case c >= 'a' && c <= 'f':
+			letter = true
+		default:
+			return false
+		}
+	}
+
+	return letter
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
 			return false
 		}
 	}
@@ -84,10 +197,64 @@ func isSigTag(s string) bool {
 }
 
 // capStrings returns out[:min(limit, len(out))] if limit>0; otherwise out.
+// A truncated result has cap == len, so appending to it reallocates instead
+// of overwriting the trimmed-off tail.
 func capStrings(out []string, limit int) []string {
 	if limit > 0 && limit < len(out) {
-		return out[:limit]
+		return out[:limit:limit]
+	}
+
+	return out
+}
+
+// capRecs is capStrings for records.
+func capRecs(out []rec, limit int) []rec {
+	if limit > 0 && limit < len(out) {
+		return out[:limit:limit]
 	}
 
 	return out
 }
+
+// window returns the [lo, hi) bounds of n results after skipping Offset
+// (negative = 0) and keeping Limit entries from the front, or from the end
+// with LimitFromEnd. An Offset past n yields an empty window.
+func window(n int, opt Options) (lo, hi int) {
+	lo, hi = min(max(opt.Offset, 0), n), n
+	if opt.Limit > 0 && opt.Limit < hi-lo {
+		if opt.LimitFromEnd {
+			lo = hi - opt.Limit
+		} else {
+			hi = lo + opt.Limit
+		}
+	}
+
+	return lo, hi
+}
+
+// trimStrings applies Offset/Limit/LimitFromEnd to out.
+func trimStrings(out []string, opt Options) []string {
+	lo, hi := window(len(out), opt)
+
+	return capStrings(out[lo:], hi-lo)
+}
+
+// trimRecs is trimStrings for records.
+func trimRecs(out []rec, opt Options) []rec {
+	lo, hi := window(len(out), opt)
+
+	return capRecs(out[lo:], hi-lo)
+}
+
+// limitRecs applies the final trim: Offset/Limit/LimitFromEnd, or Offset
+// followed by a major-spreading Limit with LimitSpreadMajors.
+func limitRecs(rs []rec, opt Options) []rec {
+	if opt.LimitSpreadMajors {
+		o := opt
+		o.Limit = 0
+
+		return spreadMajors(trimRecs(rs, o), opt.Limit)
+	}
+
+	return trimRecs(rs, opt)
+}
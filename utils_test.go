@@ -137,6 +137,13 @@ func TestCapStrings(t *testing.T) {
 	got := capStrings(orig, 2)
 	want := []string{"a", "b"}
 	assertEqSlice(t, got, want)
+	if cap(got) != len(got) {
+		t.Fatalf("capStrings cap=%d, want %d", cap(got), len(got))
+	}
+	_ = append(got, "x")
+	if orig[2] != "c" {
+		t.Fatalf("append to truncated result clobbered the input: %v", orig)
+	}
 
 	// limit > len => unchanged
 	if got := capStrings(orig, 10); !equalStrings(got, orig) {
@@ -172,6 +179,30 @@ func TestIsSigTag(t *testing.T) {
 	}
 }
 
+func TestIsDigestLike(t *testing.T) {
+	cases := []struct {
+		s    string
+		min  int
+		want bool
+	}{
+		{"abc1234", 7, true},
+		{"deadbeefcafe", 7, true},
+		{"abc123", 7, false},   // too short
+		{"abc123", 6, true},    // configurable length
+		{"1234567", 7, false},  // all digits: valid X shorthand, kept
+		{"ABC1234", 7, false},  // uppercase is not digest-like
+		{"abc1234g", 7, false}, // non-hex
+		{"v1.2.3", 3, false},
+		{"", 0, false},
+	}
+
+	for _, c := range cases {
+		if got := isDigestLike(c.s, c.min); got != c.want {
+			t.Fatalf("isDigestLike(%q,%d)=%v, want %v", c.s, c.min, got, c.want)
+		}
+	}
+}
+
 // * helpers
 
 func equalStrings(a, b []string) bool {
@@ -194,3 +225,42 @@ func assertEqSlice(t *testing.T, got, want []string) {
 		t.Fatalf("mismatch:\n got=%v\nwant=%v", got, want)
 	}
 }
+
+func TestIsSigTagWith(t *testing.T) {
+	h64 := strings.Repeat("ab01", 16)
+	h128 := h64 + h64
+	suffixes := []string{".sig", ".att", ".sbom"}
+
+	ok := []string{
+		"sha256-" + h64 + ".att",
+		"sha256-" + h64 + ".sbom",
+		"sha512-" + h128 + ".sig",
+		"sha512-" + strings.ToUpper(h128) + ".att",
+	}
+	bad := []string{
+		"sha256-" + h64[:63] + ".att",   // 63 hex
+		"sha256-" + h64 + "0.att",       // 65 hex
+		"sha512-" + h64 + ".sig",        // sha512 with 64 hex
+		"sha512-" + h128[:127] + ".sig", // 127 hex
+		"sha256-" + h128 + ".sig",       // sha256 with 128 hex
+		"sha256-" + h64,                 // no suffix
+		"sha256-" + h64 + ".sigx",       // suffix mismatch
+		"sha384-" + h64 + ".sig",        // algorithm
+	}
+
+	for _, s := range ok {
+		if !isSigTagWith(s, suffixes) {
+			t.Fatalf("want true for %q", s)
+		}
+	}
+
+	for _, s := range bad {
+		if isSigTagWith(s, suffixes) {
+			t.Fatalf("want false for %q", s)
+		}
+	}
+
+	if isSigTagWith("sha256-"+h64+".att", defaultSignatureSuffixes) {
+		t.Fatalf(".att must need an explicit suffix")
+	}
+}